@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"flag"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/urcomputeringpal/kubevalidator/validator"
@@ -42,9 +47,47 @@ func runWithContext(ctx context.Context) error {
 		PrivateKeyFile: privateKeyFile,
 	}
 
+	// A comma separated list of checkSuite, pullRequest, checkRun, push and
+	// installation. All handlers are enabled by default.
+	v.EnabledHandlers = envList("ENABLED_HANDLERS")
+
+	// Only process the check suite for the current head of a Pull Request
+	if latestOnly, ok := os.LookupEnv("LATEST_CHECK_SUITE_ONLY"); ok {
+		v.LatestCheckSuiteOnly, _ = strconv.ParseBool(latestOnly)
+	}
+
+	// A comma separated list of globs matching the image policy files and
+	// URLs repositories may configure
+	v.ImagePolicySources = envList("IMAGE_POLICY_SOURCES")
+
+	// A comma separated list of globs matching the schema locations
+	// repositories may configure
+	v.SchemaLocations = envList("SCHEMA_LOCATIONS")
+
+	// Upload reports too long for a check run summary to secret Gists with
+	// this personal access token
+	v.GistToken = os.Getenv("GIST_TOKEN")
+
+	// Fail readiness checks when the GitHub App is missing permissions
+	if checkPermissions, ok := os.LookupEnv("CHECK_PERMISSIONS"); ok {
+		v.CheckPermissions, _ = strconv.ParseBool(checkPermissions)
+	}
+
 	return v.Run(ctx)
 }
 
+// envList returns the non-empty entries of the comma separated list in the
+// environment variable name, with surrounding whitespace trimmed
+func envList(name string) []string {
+	var entries []string
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 func cancelOnInterrupt(ctx context.Context, f context.CancelFunc) {
 	term := make(chan os.Signal)
 	signal.Notify(term, os.Interrupt, syscall.SIGTERM)
@@ -69,7 +112,117 @@ func run() error {
 	return runWithContext(ctx)
 }
 
+// validate runs the validator against files on disk and returns an exit code
+// reflecting the result
+func validate(args []string) int {
+	cli := &validator.CLI{
+		Root: ".",
+		Out:  os.Stdout,
+	}
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	flags.StringVar(&cli.ConfigPath, "config", ".github/kubevalidator.yaml", "path to the kubevalidator configuration")
+	flags.StringVar(&cli.Format, "format", "text", "output format: text, snapshot, github-actions, stats or stats-by-directory")
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		cli.Root = flags.Arg(0)
+	}
+
+	annotations, err := cli.Run()
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+	if annotations.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// baseline writes a .kubevalidatorbaseline file accepting the current
+// findings of the validator and returns an exit code
+func baseline(args []string) int {
+	var out bytes.Buffer
+	cli := &validator.CLI{
+		Root: ".",
+		Out:  &out,
+	}
+	flags := flag.NewFlagSet("baseline", flag.ExitOnError)
+	flags.StringVar(&cli.ConfigPath, "config", ".github/kubevalidator.yaml", "path to the kubevalidator configuration")
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		cli.Root = flags.Arg(0)
+	}
+
+	if err := cli.Baseline(); err != nil {
+		log.Println(err)
+		return 2
+	}
+	if err := ioutil.WriteFile(filepath.Join(cli.Root, ".kubevalidatorbaseline"), out.Bytes(), 0644); err != nil {
+		log.Println(err)
+		return 2
+	}
+	return 0
+}
+
+// process validates the check suite in a single webhook event payload without
+// running a server and returns an exit code reflecting its conclusion
+func process(args []string) int {
+	o := &validator.OneShot{
+		Out: os.Stdout,
+	}
+	flags := flag.NewFlagSet("process", flag.ExitOnError)
+	flags.StringVar(&o.EventType, "event", "check_suite", "type of the webhook event in the payload")
+	flags.StringVar(&o.Format, "format", "text", "output format: text or json")
+	flags.Parse(args)
+
+	var err error
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		o.Payload, err = ioutil.ReadFile(flags.Arg(0))
+	} else {
+		o.Payload, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+
+	appID, ok := os.LookupEnv("APP_ID")
+	if !ok {
+		log.Println("APP_ID required")
+		return 2
+	}
+	o.AppID, _ = strconv.Atoi(appID)
+	o.PrivateKeyFile, ok = os.LookupEnv("PRIVATE_KEY_FILE")
+	if !ok {
+		log.Println("PRIVATE_KEY_FILE required")
+		return 2
+	}
+
+	o.ImagePolicySources = envList("IMAGE_POLICY_SOURCES")
+	o.SchemaLocations = envList("SCHEMA_LOCATIONS")
+
+	conclusion, err := o.Run(context.Background())
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+	if conclusion == "failure" {
+		return 1
+	}
+	return 0
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "baseline" {
+		os.Exit(baseline(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "process" {
+		os.Exit(process(os.Args[2:]))
+	}
+
 	if err := run(); err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 		panic(err)
 	}
@@ -6,6 +6,13 @@ import (
 	"github.com/google/go-github/github"
 )
 
+// Annotation levels supported by GitHub
+const (
+	levelNotice  = "notice"
+	levelWarning = "warning"
+	levelFailure = "failure"
+)
+
 // Annotations is an array of pointers to CheckRunAnnotations
 type Annotations []*github.CheckRunAnnotation
 
@@ -13,10 +20,55 @@ func (a Annotations) Len() int {
 	return len(a)
 }
 func (a Annotations) Swap(i, j int) {
-	*a[i], *a[j] = *a[j], *a[i]
+	a[i], a[j] = a[j], a[i]
 }
 func (a Annotations) Less(i, j int) bool {
 	one := fmt.Sprintf("%d:%s", a[i].GetStartLine(), a[i].GetMessage())
 	two := fmt.Sprintf("%d:%s", a[j].GetStartLine(), a[j].GetMessage())
 	return one < two
 }
+
+// count returns the number of annotations with the given level
+func (a Annotations) count(level string) int {
+	n := 0
+	for _, annotation := range a {
+		if annotation.GetAnnotationLevel() == level {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns true when any annotation has the failure level
+func (a Annotations) Failed() bool {
+	return a.count(levelFailure) > 0
+}
+
+// truncateMessages shortens messages longer than max runes, appending an
+// ellipsis. The full message is preserved at the start of RawDetails.
+func (a Annotations) truncateMessages(max int) {
+	if max <= 0 {
+		return
+	}
+	for _, annotation := range a {
+		message := []rune(annotation.GetMessage())
+		if len(message) <= max {
+			continue
+		}
+		details := string(message)
+		if annotation.GetRawDetails() != "" {
+			details = fmt.Sprintf("%s\n\n%s", details, annotation.GetRawDetails())
+		}
+		annotation.RawDetails = github.String(details)
+		annotation.Message = github.String(truncateMessage(string(message), max))
+	}
+}
+
+// truncateMessage shortens s to max runes, ending with an ellipsis
+func truncateMessage(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
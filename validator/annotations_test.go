@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestTruncateMessages(t *testing.T) {
+	long := "spec.template.spec.containers.0.resources.limits.memory: Invalid type"
+	annotations := Annotations{
+		{Message: github.String(long), RawDetails: github.String("* field: memory\n")},
+		{Message: github.String("short")},
+	}
+	annotations.truncateMessages(20)
+
+	if got := annotations[0].GetMessage(); got != "spec.template.spec.…" {
+		t.Errorf("expected a truncated message, got %s", got)
+	}
+	if got := annotations[0].GetRawDetails(); got != long+"\n\n* field: memory\n" {
+		t.Errorf("expected the full message in raw details, got %s", got)
+	}
+	if got := annotations[1].GetMessage(); got != "short" {
+		t.Errorf("expected short messages to be untouched, got %s", got)
+	}
+	if annotations[1].RawDetails != nil {
+		t.Errorf("expected no raw details, got %s", annotations[1].GetRawDetails())
+	}
+}
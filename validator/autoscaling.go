@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const replicasWithAutoscalerTitle = "Replicas managed by an autoscaler"
+
+// scalableKinds lists the kinds of workloads that a HorizontalPodAutoscaler
+// can target
+var scalableKinds = map[string]bool{
+	"Deployment":  true,
+	"ReplicaSet":  true,
+	"StatefulSet": true,
+}
+
+// autoscaler returns the HorizontalPodAutoscaler in resources targeting r, if
+// any
+func (r *Resource) autoscaler(resources []*Resource) *Resource {
+	for _, hpa := range resources {
+		if hpa.Kind() != "HorizontalPodAutoscaler" || hpa.Namespace() != r.Namespace() {
+			continue
+		}
+		if hpa.getString("spec", "scaleTargetRef", "kind") == r.Kind() && hpa.getString("spec", "scaleTargetRef", "name") == r.Name() {
+			return hpa
+		}
+	}
+	return nil
+}
+
+// autoscalerAnnotation returns the first annotation of r whose key matches
+// one of globs, if any
+func (r *Resource) autoscalerAnnotation(globs []string) (string, bool) {
+	for _, key := range sortedKeys(stringMap(r.get("metadata", "annotations"))) {
+		for _, glob := range globs {
+			if matched, _ := doublestar.Match(glob, key); matched {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkReplicasWithAutoscaler warns when a workload sets replicas while a
+// HorizontalPodAutoscaler in the Pull Request targets it, or while it carries
+// an annotation of another autoscaler, as every apply resets the number of
+// replicas chosen by the autoscaler.
+func checkReplicasWithAutoscaler(r *Resource, resources []*Resource) Annotations {
+	if !scalableKinds[r.Kind()] || r.get("spec", "replicas") == nil {
+		return nil
+	}
+	policy := r.policies().ReplicasWithAutoscaler
+	level := levelWarning
+	var annotationGlobs []string
+	if policy != nil {
+		level = policy.level(levelWarning)
+		annotationGlobs = policy.Annotations
+	}
+	if level == "" {
+		return nil
+	}
+
+	if hpa := r.autoscaler(resources); hpa != nil {
+		return Annotations{r.annotation(level, replicasWithAutoscalerTitle,
+			fmt.Sprintf("%s sets replicas to %v, but is scaled by %s (minReplicas %v). Remove replicas so that applying %s doesn't override the autoscaler.",
+				r, r.get("spec", "replicas"), hpa, hpa.get("spec", "minReplicas"), r),
+			"spec", "replicas")}
+	}
+	if key, ok := r.autoscalerAnnotation(annotationGlobs); ok {
+		return Annotations{r.annotation(level, replicasWithAutoscalerTitle,
+			fmt.Sprintf("%s sets replicas to %v, but its %s annotation hands its replicas to an autoscaler. Remove replicas so that applying %s doesn't override the autoscaler.",
+				r, r.get("spec", "replicas"), key, r),
+			"spec", "replicas")}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestReplicasWithAutoscaler(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/autoscaling/hpa.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/autoscaling/hpa.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestReplicasWithAutoscalerCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ReplicasWithAutoscaler: &KubeValidatorConfigReplicasWithAutoscaler{
+			KubeValidatorConfigRule: KubeValidatorConfigRule{Level: "off"},
+		},
+	}, "fixtures/checks/autoscaling/hpa.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestReplicasWithAutoscalerAnnotation(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ReplicasWithAutoscaler: &KubeValidatorConfigReplicasWithAutoscaler{
+			Annotations: []string{"autoscaling.keda.sh/*"},
+		},
+	}, "fixtures/checks/autoscaling/annotations.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/autoscaling/annotations.yaml"),
+		StartLine:       github.Int(8),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestReplicasWithAutoscalerAnnotationsAreConfigured(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/autoscaling/annotations.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
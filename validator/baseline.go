@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const baselinePath = ".kubevalidatorbaseline"
+
+// baseline contains accepted findings, one per line of a .kubevalidatorbaseline
+// file. Matching annotations are suppressed so that only new findings are
+// reported.
+type baseline map[string]bool
+
+// parseBaselineFile parses the contents of a .kubevalidatorbaseline file
+func parseBaselineFile(b []byte) baseline {
+	entries := baseline{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries[line] = true
+	}
+	return entries
+}
+
+// baselineEntry identifies an annotation by its file, rule and a hash of its
+// location. The hash covers the message and the contents of the annotated
+// lines rather than their numbers, so that entries survive unrelated changes
+// elsewhere in the file.
+func (c *Candidates) baselineEntry(a *github.CheckRunAnnotation) string {
+	hash := sha256.New()
+	io.WriteString(hash, a.GetMessage())
+	for _, line := range c.annotatedLines(a) {
+		io.WriteString(hash, "\n"+strings.TrimSpace(line))
+	}
+	return fmt.Sprintf("%s\t%s\t%x", a.GetPath(), a.GetTitle(), hash.Sum(nil)[:8])
+}
+
+// suppressBaselined returns the annotations which aren't in the baseline
+func (c *Candidates) suppressBaselined(annotations Annotations, accepted baseline) Annotations {
+	if len(accepted) == 0 {
+		return annotations
+	}
+	var remaining Annotations
+	for _, a := range annotations {
+		if !accepted[c.baselineEntry(a)] {
+			remaining = append(remaining, a)
+		}
+	}
+	return remaining
+}
+
+// writeBaseline writes a .kubevalidatorbaseline file accepting every
+// annotation
+func (c *Candidates) writeBaseline(w io.Writer, annotations Annotations) error {
+	var entries []string
+	seen := map[string]bool{}
+	for _, a := range annotations {
+		entry := c.baselineEntry(a)
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.Strings(entries)
+
+	if _, err := fmt.Fprintln(w, "# Findings accepted by kubevalidator. Regenerate with `kubevalidator baseline`."); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := fmt.Fprintln(w, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestBaselineSuppressesAcceptedFindings(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/ingress/collisions.yaml")
+	annotations := candidates.Check()
+	if len(annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(annotations))
+	}
+
+	var b bytes.Buffer
+	if err := candidates.writeBaseline(&b, annotations[:1]); err != nil {
+		t.Fatal(err)
+	}
+	accepted := parseBaselineFile(b.Bytes())
+	if len(accepted) != 1 {
+		t.Fatalf("expected a baseline with 1 finding, got %s", b.String())
+	}
+
+	path := github.String("fixtures/checks/ingress/collisions.yaml")
+	wantAnnotations(t, candidates.suppressBaselined(annotations, accepted),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(29), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestBaselineEntriesIgnoreLineNumbers(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/ingress/collisions.yaml")
+	annotations := candidates.Check()
+
+	var b bytes.Buffer
+	if err := candidates.writeBaseline(&b, annotations); err != nil {
+		t.Fatal(err)
+	}
+
+	shifted := append([]byte("# A comment moving every line down\n"), *candidates[0].bytes...)
+	candidates[0].setBytes(&shifted)
+	if remaining := candidates.suppressBaselined(candidates.Check(), parseBaselineFile(b.Bytes())); len(remaining) != 0 {
+		t.Errorf("expected findings on shifted lines to remain accepted, got %s", github.Stringify(remaining))
+	}
+}
@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	invalidScheduleTitle = "Invalid CronJob schedule"
+	invalidJobTitle      = "Invalid Job configuration"
+)
+
+var (
+	jobRestartPolicies  = []string{"OnFailure", "Never"}
+	concurrencyPolicies = []string{"Allow", "Forbid", "Replace"}
+)
+
+// cronField describes the values accepted by a field of a cron expression
+type cronField struct {
+	name     string
+	min      int
+	max      int
+	names    []string
+	anyValue bool
+}
+
+var (
+	cronFields = []cronField{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31, anyValue: true},
+		{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+		{name: "day of week", min: 0, max: 6, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}, anyValue: true},
+	}
+
+	cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+)
+
+// parseCronSchedule returns an error when schedule isn't a cron expression
+// accepted by the CronJob controller
+func parseCronSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if strings.HasPrefix(schedule, "@") {
+		if strings.HasPrefix(schedule, "@every ") {
+			_, err := time.ParseDuration(strings.TrimPrefix(schedule, "@every "))
+			return err
+		}
+		if !containsString(cronDescriptors, schedule) {
+			return fmt.Errorf("unrecognized descriptor %s", schedule)
+		}
+		return nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected exactly %d fields, found %d: %s", len(cronFields), len(fields), schedule)
+	}
+	for i, field := range fields {
+		if err := cronFields[i].parse(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parse returns an error when s isn't a valid list of ranges for the field
+func (f cronField) parse(s string) error {
+	for _, expression := range strings.Split(s, ",") {
+		rangeExpression := expression
+		if index := strings.Index(expression, "/"); index >= 0 {
+			rangeExpression = expression[:index]
+			step, err := strconv.Atoi(expression[index+1:])
+			if err != nil || step <= 0 {
+				return fmt.Errorf("invalid step in %s field: %s", f.name, expression)
+			}
+		}
+		if rangeExpression == "*" || (rangeExpression == "?" && f.anyValue) {
+			continue
+		}
+		bounds := strings.SplitN(rangeExpression, "-", 2)
+		var values []int
+		for _, bound := range bounds {
+			value, err := f.value(bound)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 2 && values[0] > values[1] {
+			return fmt.Errorf("beginning of range %s is after its end in %s field", rangeExpression, f.name)
+		}
+	}
+	return nil
+}
+
+// value parses a single number or name within the bounds of the field
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.ToLower(s) == name {
+			return f.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s field: %s", f.name, s)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s field %d is out of range (%d-%d)", f.name, value, f.min, f.max)
+	}
+	return value, nil
+}
+
+// checkCronJobSchedule fails when the schedule of a CronJob can't be parsed,
+// as the CronJob would never run.
+func checkCronJobSchedule(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "CronJob" {
+		return nil
+	}
+	level := r.policies().CronJobSchedules.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	schedule, ok := r.get("spec", "schedule").(string)
+	if !ok {
+		return nil
+	}
+	if err := parseCronSchedule(schedule); err != nil {
+		return Annotations{r.annotation(level, invalidScheduleTitle,
+			fmt.Sprintf("The schedule of %s is invalid: %s", r, err),
+			"spec", "schedule")}
+	}
+	return nil
+}
+
+// jobSpecPath returns the path to the Job spec of Jobs and CronJobs
+func (r *Resource) jobSpecPath() ([]interface{}, bool) {
+	switch r.Kind() {
+	case "Job":
+		return []interface{}{"spec"}, true
+	case "CronJob":
+		return []interface{}{"spec", "jobTemplate", "spec"}, true
+	}
+	return nil, false
+}
+
+// checkJobSpec fails when a Job or CronJob uses a restartPolicy other than
+// OnFailure or Never, a negative or excessive backoffLimit, or an unknown
+// concurrencyPolicy.
+func checkJobSpec(r *Resource, resources []*Resource) Annotations {
+	jobSpec, ok := r.jobSpecPath()
+	if !ok {
+		return nil
+	}
+	policy := r.policies().JobSpecs
+	level := levelFailure
+	if policy != nil {
+		level = policy.level(levelFailure)
+	}
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	podSpec, _ := r.podSpecPath()
+	restartPolicy := r.getString(joinPath(podSpec, "restartPolicy")...)
+	switch {
+	case restartPolicy == "":
+		annotations = append(annotations, r.annotation(level, invalidJobTitle,
+			fmt.Sprintf("The pods of %s must set restartPolicy to one of: %s", r, strings.Join(jobRestartPolicies, ", ")),
+			podSpec...))
+	case !containsString(jobRestartPolicies, restartPolicy):
+		annotations = append(annotations, r.annotation(level, invalidJobTitle,
+			fmt.Sprintf("The pods of %s use the %s restartPolicy, which isn't one of: %s", r, restartPolicy, strings.Join(jobRestartPolicies, ", ")),
+			joinPath(podSpec, "restartPolicy")...))
+	}
+
+	if backoffLimit, ok := intValue(r.get(joinPath(jobSpec, "backoffLimit")...)); ok {
+		switch {
+		case backoffLimit < 0:
+			annotations = append(annotations, r.annotation(level, invalidJobTitle,
+				fmt.Sprintf("The backoffLimit of %s must not be negative", r),
+				joinPath(jobSpec, "backoffLimit")...))
+		case policy.maxBackoffLimit() > 0 && backoffLimit > policy.maxBackoffLimit():
+			annotations = append(annotations, r.annotation(level, invalidJobTitle,
+				fmt.Sprintf("The backoffLimit of %s is %d, more than the maximum of %d", r, backoffLimit, policy.maxBackoffLimit()),
+				joinPath(jobSpec, "backoffLimit")...))
+		}
+	}
+
+	if r.Kind() == "CronJob" {
+		concurrencyPolicy := r.getString("spec", "concurrencyPolicy")
+		if concurrencyPolicy != "" && !containsString(concurrencyPolicies, concurrencyPolicy) {
+			annotations = append(annotations, r.annotation(level, invalidJobTitle,
+				fmt.Sprintf("The concurrencyPolicy of %s is %s, which isn't one of: %s", r, concurrencyPolicy, strings.Join(concurrencyPolicies, ", ")),
+				"spec", "concurrencyPolicy"))
+		}
+	}
+	return annotations
+}
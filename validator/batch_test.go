@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 0-6 1,15 * mon-fri", "0 0 ? JAN sun", "@hourly", "@every 5m", "0 12 * * 6"}
+	for _, schedule := range valid {
+		if err := parseCronSchedule(schedule); err != nil {
+			t.Errorf("%s: unexpected error %s", schedule, err)
+		}
+	}
+
+	invalid := []string{"* * * *", "60 * * * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "? * * * *", "@fortnightly", "@every forever", "0 12 * * 7"}
+	for _, schedule := range invalid {
+		if err := parseCronSchedule(schedule); err == nil {
+			t.Errorf("%s: expected an error", schedule)
+		}
+	}
+}
+
+func TestCronJobSchedule(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/batch/schedule.yaml")
+	annotations := candidates.Check()
+
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/batch/schedule.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("failure"),
+	})
+	if len(annotations) == 1 && !strings.Contains(annotations[0].GetMessage(), "expected exactly 5 fields, found 4") {
+		t.Errorf("expected the parse error in the message, got %s", annotations[0].GetMessage())
+	}
+}
+
+func TestJobSpec(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/batch/jobs.yaml")
+	annotations := candidates.Check()
+
+	path := github.String("fixtures/checks/batch/jobs.yaml")
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(20), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(9), AnnotationLevel: github.String("failure")},
+	)
+	if len(annotations) == 2 && !strings.Contains(annotations[1].GetMessage(), "use the Always restartPolicy") {
+		t.Errorf("expected the restartPolicy in the message, got %s", annotations[1].GetMessage())
+	}
+}
+
+func TestJobSpecMaxBackoffLimit(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		JobSpecs: &KubeValidatorConfigJobSpecs{MaxBackoffLimit: 10},
+	}, "fixtures/checks/batch/jobs.yaml")
+
+	path := github.String("fixtures/checks/batch/jobs.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(20), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(23), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(9), AnnotationLevel: github.String("failure")},
+	)
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/garethr/kubeval/kubeval"
@@ -12,19 +14,39 @@ import (
 	yamlpatch "github.com/krishicks/yaml-patch"
 	difflib "github.com/pmezard/go-difflib/difflib"
 	"github.com/xeipuuv/gojsonschema"
+	yaml "gopkg.in/yaml.v2"
 	"sourcegraph.com/sourcegraph/go-diff/diff"
 )
 
 // Candidate reprensets a file to be validated
 type Candidate struct {
-	bytes   *[]byte
-	context *Context
-	file    *github.CommitFile
-	schemas []*KubeValidatorConfigSchema
+	bytes     *[]byte
+	context   *Context
+	file      *github.CommitFile
+	schemas   []*KubeValidatorConfigSchema
+	policies  *KubeValidatorConfigPolicies
+	resources []*Resource
+	crds      crdIndex
+
+	imagePolicy *imagePolicyResult
+
+	// schemaLocationAllowed returns true for the schema locations that may
+	// be loaded. No locations are allowed when nil.
+	schemaLocationAllowed func(string) bool
+
+	schemaSets           []*KubeValidatorConfigSchemaSet
+	schemaSetConclusion  string
+	schemaSetAnnotations map[string]Annotations
+
+	baseBytes     *[]byte
+	baseResources []*Resource
+	baseLoaded    bool
 }
 
 const (
 	placeholderString = "AAA___KUBEVALIDATOR___PLACEHOLDER___AAA"
+
+	schemaLocationNotAllowedTitle = "Schema location not allowed"
 )
 
 var (
@@ -49,6 +71,7 @@ func NewCandidate(context *Context, file *github.CommitFile, schemas []*KubeVali
 
 func (c *Candidate) setBytes(b *[]byte) {
 	c.bytes = b
+	c.resources = nil
 }
 
 // LoadBytes hydrates bytes from GitHub and returns a CheckRunAnnotation if
@@ -67,10 +90,70 @@ func (c *Candidate) LoadBytes() *github.CheckRunAnnotation {
 		}
 	}
 
-	c.bytes = b
+	c.setBytes(b)
 	return nil
 }
 
+func (c *Candidate) setBaseBytes(b *[]byte) {
+	c.baseBytes = b
+	c.baseResources = nil
+	c.baseLoaded = true
+}
+
+// loadBaseBytes hydrates the bytes of modified and renamed files from the base
+// of the Pull Request, where renamed files had their previous name. Checks
+// comparing against the base are skipped when this fails.
+func (c *Candidate) loadBaseBytes() {
+	c.baseLoaded = true
+	e, ok := c.context.Event.(*github.CheckSuiteEvent)
+	if !ok {
+		return
+	}
+	filename := c.file.GetFilename()
+	switch c.file.GetStatus() {
+	case "modified":
+	case "renamed":
+		filename = c.context.previousFilenames[filename]
+	default:
+		return
+	}
+	ref := baseSHA(e)
+	if ref == "" || filename == "" {
+		return
+	}
+	b, err := c.context.bytesForFilenameAtRef(e, filename, ref)
+	if err != nil {
+		return
+	}
+	c.setBaseBytes(b)
+}
+
+// baseResource returns the Resource with the same kind, namespace and name as
+// r in the base of the Pull Request, if any. The base is only loaded from
+// GitHub the first time it's needed.
+func (c *Candidate) baseResource(r *Resource) *Resource {
+	if !c.baseLoaded {
+		c.loadBaseBytes()
+	}
+	if c.baseResources == nil && c.baseBytes != nil {
+		c.baseResources = parseResources(c, *c.baseBytes)
+	}
+	for _, base := range c.baseResources {
+		if base.Kind() == r.Kind() && base.Namespace() == r.Namespace() && base.Name() == r.Name() {
+			return base
+		}
+	}
+	return nil
+}
+
+// Resources returns the Kubernetes resources contained in the Candidate
+func (c *Candidate) Resources() []*Resource {
+	if c.resources == nil && c.bytes != nil && !c.isHelmChart() {
+		c.resources = parseResources(c, *c.bytes)
+	}
+	return c.resources
+}
+
 // MarkdownListItem returns a string that represents the Candidate designed for
 // use in a Markdown List
 func (c *Candidate) MarkdownListItem() string {
@@ -79,86 +162,264 @@ func (c *Candidate) MarkdownListItem() string {
 
 // Validate bytes with kubeval and return an array of CheckRunAnnotation
 func (c *Candidate) Validate() Annotations {
+	if c.isHelmChart() && c.bytes != nil {
+		return c.validateHelmChart()
+	}
+
 	var annotations Annotations
+	var documents []document
+	if c.bytes != nil {
+		documents, annotations = c.kubernetesDocuments()
+		annotations = append(annotations, c.styleAnnotations()...)
+	}
 	for _, schema := range c.schemas {
-		kubeval.SchemaLocation = schema.SchemaLocation()
-
-		// TODO move more of this into KubeValidatorConfigSchema
-		if schema.Version != "" {
-			kubeval.Version = schema.Version
+		annotations = append(annotations, c.validateSchema(schema, schema.name(), documents)...)
+	}
+	c.schemaSetAnnotations = map[string]Annotations{}
+	for _, set := range c.schemaSets {
+		for _, schema := range set.Schemas {
+			setAnnotations := c.validateSchema(schema, fmt.Sprintf("%s/%s", set.Name, schema.name()), documents)
+			c.schemaSetAnnotations[set.Name] = append(c.schemaSetAnnotations[set.Name], setAnnotations...)
+			annotations = append(annotations, setAnnotations...)
 		}
+	}
+	sort.Sort(annotations)
+	return annotations
+}
 
-		// TODO configurable
-		kubeval.Strict = true
-		if schema.ConfigType == "openstack" {
-			kubeval.OpenShift = true
-		} else {
-			kubeval.OpenShift = false
+// schemaAllowed returns false when schema configures a location that isn't
+// allowed
+func (c *Candidate) schemaAllowed(schema *KubeValidatorConfigSchema) bool {
+	for _, location := range []string{schema.Location, schema.GatewayAPILocation} {
+		if location != "" && (c.schemaLocationAllowed == nil || !c.schemaLocationAllowed(location)) {
+			return false
 		}
+	}
+	return true
+}
 
-		var schemaName string
-		if schema.Name != "" {
-			schemaName = schema.Name
-		} else if schema.Version != "" {
-			schemaName = schema.Version
-		} else {
-			schemaName = "default"
-		}
+// validateSchema validates documents against a single schema
+func (c *Candidate) validateSchema(schema *KubeValidatorConfigSchema, schemaName string, documents []document) Annotations {
+	if !c.schemaAllowed(schema) {
+		return Annotations{&github.CheckRunAnnotation{
+			Path:            c.file.Filename,
+			BlobHRef:        c.file.BlobURL,
+			StartLine:       github.Int(1),
+			EndLine:         github.Int(1),
+			AnnotationLevel: github.String(levelFailure),
+			Title:           github.String(schemaLocationNotAllowedTitle),
+			Message:         github.String(fmt.Sprintf("This file couldn't be validated against the %s schema because its location isn't one of the locations allowed by the operator of kubevalidator.", schemaName)),
+		}}
+	}
+	kubeval.SchemaLocation = schema.SchemaLocation()
 
-		if c.bytes == nil {
-			annotations = append(annotations, &github.CheckRunAnnotation{
-				Path:            c.file.Filename,
-				BlobHRef:        c.file.BlobURL,
-				StartLine:       github.Int(1),
-				EndLine:         github.Int(1),
-				AnnotationLevel: github.String("failure"),
-				Title:           github.String("Candidate has no bytes?"),
-				Message:         github.String(fmt.Sprintf("%+v", c)),
-			})
+	// TODO move more of this into KubeValidatorConfigSchema
+	if schema.Version != "" {
+		kubeval.Version = schema.Version
+	}
+
+	// TODO configurable
+	kubeval.Strict = true
+	if schema.ConfigType == "openstack" {
+		kubeval.OpenShift = true
+	} else {
+		kubeval.OpenShift = false
+	}
+
+	if c.bytes == nil {
+		return Annotations{&github.CheckRunAnnotation{
+			Path:            c.file.Filename,
+			BlobHRef:        c.file.BlobURL,
+			StartLine:       github.Int(1),
+			EndLine:         github.Int(1),
+			AnnotationLevel: github.String("failure"),
+			Title:           github.String("Candidate has no bytes?"),
+			Message:         github.String(fmt.Sprintf("%+v", c)),
+		}}
+	}
+
+	var annotations Annotations
+	for _, d := range documents {
+		annotations = append(annotations, c.validateDocument(d, schema, schemaName)...)
+	}
+	return annotations
+}
+
+// kubernetesDocuments returns the documents in the Candidate which should be
+// validated against schemas. Empty documents are skipped, as are documents
+// that aren't Kubernetes resources. The latter are annotated unless the
+// nonResources policy is off. Documents that can't be parsed are annotated
+// once rather than validated against every schema.
+func (c *Candidate) kubernetesDocuments() ([]document, Annotations) {
+	var documents []document
+	var annotations Annotations
+	var parseErrors Annotations
+	parsed := false
+	level := c.getPolicies().NonResources.level(levelFailure)
+	for _, d := range splitDocuments(*c.bytes) {
+		var body interface{}
+		if err := yaml.Unmarshal(d.bytes, &body); err != nil {
+			parseErrors = append(parseErrors, c.parseErrorAnnotation(d, err))
+			continue
+		}
+		if body == nil {
+			continue
+		}
+		parsed = true
+		if annotation := c.malformedDocumentAnnotation(d); annotation != nil {
+			annotations = append(annotations, annotation)
+			continue
+		}
+		r := parseResource(c, d.bytes, d.offset)
+		if r != nil && r.isKubernetesResource() {
+			documents = append(documents, d)
+			continue
+		}
+		if level == "" {
 			continue
 		}
+		message := "This document doesn't specify an apiVersion and kind, so it can't be validated against a schema."
+		if root, ok := body.(map[interface{}]interface{}); ok && len(root) > 1 {
+			var keys []string
+			for key := range root {
+				keys = append(keys, fmt.Sprintf("%v", key))
+			}
+			sort.Strings(keys)
+			message = fmt.Sprintf("This document contains the top-level keys %s rather than a single resource. It doesn't specify an apiVersion and kind, so it can't be validated against a schema.", strings.Join(keys, ", "))
+		}
+		line := d.offset + 1
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            c.file.Filename,
+			BlobHRef:        c.file.BlobURL,
+			StartLine:       github.Int(line),
+			EndLine:         github.Int(line),
+			AnnotationLevel: github.String(level),
+			Title:           github.String(nonResourceTitle),
+			Message:         github.String(message),
+		})
+	}
+
+	// A file that can't be parsed at all is reported once, rather than once
+	// for every fragment between document separators
+	if !parsed && len(parseErrors) > 0 {
+		first := parseErrors[0]
+		first.Message = github.String(fmt.Sprintf("%s couldn't be parsed: %s", c.file.GetFilename(), first.GetMessage()))
+		parseErrors = parseErrors[:1]
+	}
+	return documents, append(parseErrors, annotations...)
+}
+
+const (
+	nonResourceTitle       = "Not a Kubernetes resource"
+	parseErrorTitle        = "Invalid YAML"
+	malformedDocumentTitle = "Multiple resources in one document"
+)
+
+// malformedDocumentAnnotation annotates a document with duplicate top-level
+// keys, which is only partially parsed, unless the malformedDocuments policy
+// is off
+func (c *Candidate) malformedDocumentAnnotation(d document) *github.CheckRunAnnotation {
+	level := c.getPolicies().MalformedDocuments.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+	key, line, ok := duplicateRootKey(tokenizeYAML(d.bytes))
+	if !ok {
+		return nil
+	}
+	line += d.offset
+	return &github.CheckRunAnnotation{
+		Path:            c.file.Filename,
+		BlobHRef:        c.file.BlobURL,
+		StartLine:       github.Int(line),
+		EndLine:         github.Int(line),
+		AnnotationLevel: github.String(level),
+		Title:           github.String(malformedDocumentTitle),
+		Message:         github.String(fmt.Sprintf("The top-level key %s appears more than once in this document, so only part of it would be validated. Separate resources with ---.", key)),
+	}
+}
+
+// parseErrorLine matches the line number in errors returned by the YAML parser
+var parseErrorLine = regexp.MustCompile(`line ([0-9]+)`)
+
+// parseErrorAnnotation annotates a document which can't be parsed, at the
+// line of the error when the parser reports one
+func (c *Candidate) parseErrorAnnotation(d document, err error) *github.CheckRunAnnotation {
+	line := 1
+	if match := parseErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+	line += d.offset
+	return &github.CheckRunAnnotation{
+		Path:            c.file.Filename,
+		BlobHRef:        c.file.BlobURL,
+		StartLine:       github.Int(line),
+		EndLine:         github.Int(line),
+		AnnotationLevel: github.String(levelFailure),
+		Title:           github.String(parseErrorTitle),
+		Message:         github.String(strings.TrimPrefix(err.Error(), "yaml: ")),
+	}
+}
+
+// validateDocument validates a single document with kubeval, or against the
+// Gateway API CustomResourceDefinitions when configured. kubeval's global
+// options must already be configured for schema.
+func (c *Candidate) validateDocument(d document, schema *KubeValidatorConfigSchema, schemaName string) Annotations {
+	var annotations Annotations
+	var results []kubeval.ValidationResult
+	var err error
+	r := parseResource(c, d.bytes, d.offset)
+	if r != nil && schema.GatewayAPIVersion != "" && isGatewayAPIResource(r.APIVersion()) {
+		results, err = validateGatewayAPI(r, schema)
+	} else if crd, version := c.crds.lookup(r); crd != nil {
+		results, err = validateCustomResource(r, crd, version)
+	} else {
+		results, err = kubeval.Validate(d.bytes, c.file.GetFilename())
+	}
+
+	if err != nil {
+		var kind string
+		if len(results) > 0 {
+			kind = results[0].Kind
+		}
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            c.file.Filename,
+			BlobHRef:        c.file.BlobURL,
+			StartLine:       github.Int(d.offset + 1),
+			EndLine:         github.Int(d.offset + 1),
+			AnnotationLevel: github.String("failure"),
+			Title:           github.String(fmt.Sprintf("Error validating %s against %s schema", kind, schemaName)),
+			Message:         github.String(fmt.Sprintf("%+v", err)),
+		})
+		return annotations
+	}
 
-		results, err := kubeval.Validate(*c.bytes, c.file.GetFilename())
+	for _, result := range results {
+		for _, error := range result.Errors {
+			startLine := 1
+			endLine := 1
+			if schema.LineNumbers == true {
+				switch error.Type() {
+				default:
+					// fmt.Println(error.Type())
+					startLine, endLine = detectLineNumbersDefault(&d.bytes, error)
+				}
+			}
+			startLine += d.offset
+			endLine += d.offset
 
-		if err != nil {
 			annotations = append(annotations, &github.CheckRunAnnotation{
 				Path:            c.file.Filename,
 				BlobHRef:        c.file.BlobURL,
-				StartLine:       github.Int(1),
-				EndLine:         github.Int(1),
+				StartLine:       github.Int(startLine),
+				EndLine:         github.Int(endLine),
 				AnnotationLevel: github.String("failure"),
-				Title:           github.String(fmt.Sprintf("Error validating %s against %s schema", results[0].Kind, schemaName)),
-				Message:         github.String(fmt.Sprintf("%+v", err)),
+				Title:           github.String(fmt.Sprintf("Error validating %s against %s schema", result.Kind, schemaName)),
+				Message:         github.String(error.String()),
+				RawDetails:      github.String(resultErrorDetailString(error)),
 			})
-			continue
-		}
-
-		for _, result := range results {
-			for _, error := range result.Errors {
-				startLine := 1
-				endLine := 1
-				if schema.LineNumbers == true {
-					switch error.Type() {
-					default:
-						// fmt.Println(error.Type())
-						startLine, endLine = detectLineNumbersDefault(c.bytes, error)
-					}
-				}
-
-				annotations = append(annotations, &github.CheckRunAnnotation{
-					Path:            c.file.Filename,
-					BlobHRef:        c.file.BlobURL,
-					StartLine:       &startLine,
-					EndLine:         &endLine,
-					AnnotationLevel: github.String("failure"),
-					Title:           github.String(fmt.Sprintf("Error validating %s against %s schema", result.Kind, schemaName)),
-					Message:         github.String(error.String()),
-					RawDetails:      github.String(resultErrorDetailString(error)),
-				})
-			}
 		}
 	}
-	sort.Sort(annotations)
 	return annotations
 }
 
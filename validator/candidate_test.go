@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -203,3 +204,65 @@ func TestAnnotationsWithCustomSchemaFailure(t *testing.T) {
 		}
 	}
 }
+
+// withoutSchemas removes the schemas from candidates so that they can be
+// validated without fetching schemas
+func withoutSchemas(candidates Candidates) Candidates {
+	for _, candidate := range candidates {
+		candidate.schemas = nil
+	}
+	return candidates
+}
+
+func TestNonResourceDocuments(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/resources/values.yaml", "fixtures/checks/resources/mixed.yaml"))
+	wantAnnotations(t, candidates.Validate(),
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/values.yaml"), StartLine: github.Int(1), AnnotationLevel: github.String(levelFailure)},
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/mixed.yaml"), StartLine: github.Int(8), AnnotationLevel: github.String(levelFailure)},
+	)
+
+	if total, _ := candidates.resourceCounts(); total != 1 {
+		t.Errorf("expected 1 resource, got %d", total)
+	}
+
+	candidates = withoutSchemas(fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NonResources: &KubeValidatorConfigRule{Level: levelOff},
+	}, "fixtures/checks/resources/values.yaml", "fixtures/checks/resources/mixed.yaml"))
+	wantAnnotations(t, candidates.Validate())
+}
+
+func TestUnparseableFileIsAnnotatedOnce(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/resources/invalid.yaml")
+	annotations := candidates.Validate()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/invalid.yaml"), StartLine: github.Int(5), AnnotationLevel: github.String(levelFailure)},
+	)
+	if len(annotations) == 1 && annotations[0].GetTitle() != parseErrorTitle {
+		t.Errorf("expected a parse error, got %s", annotations[0].GetTitle())
+	}
+
+	candidates = withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/resources/partial.yaml"))
+	wantAnnotations(t, candidates.Validate(),
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/partial.yaml"), StartLine: github.Int(9), AnnotationLevel: github.String(levelFailure)},
+	)
+}
+
+func TestMalformedDocuments(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/resources/concatenated.yaml", "fixtures/checks/resources/roots.yaml"))
+	annotations := candidates.Validate()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/roots.yaml"), StartLine: github.Int(1), AnnotationLevel: github.String(levelFailure)},
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/resources/concatenated.yaml"), StartLine: github.Int(7), AnnotationLevel: github.String(levelFailure)},
+	)
+	if len(annotations) == 2 {
+		if want := "top-level keys image, replicaCount, service"; !strings.Contains(annotations[0].GetMessage(), want) {
+			t.Errorf("expected %q in %s", want, annotations[0].GetMessage())
+		}
+		if annotations[1].GetTitle() != malformedDocumentTitle {
+			t.Errorf("expected a malformed document, got %s", annotations[1].GetTitle())
+		}
+	}
+	if total, _ := candidates.resourceCounts(); total != 0 {
+		t.Errorf("expected the malformed document not to be checked, got %d resources", total)
+	}
+}
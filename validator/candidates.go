@@ -1,6 +1,14 @@
 package validator
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const candidatesLimitTitle = "Files not validated"
 
 // Candidates is an array of pointers to Candidates
 type Candidates []*Candidate
@@ -18,8 +26,17 @@ func (c *Candidates) LoadBytes() Annotations {
 	return a
 }
 
-// Validate runs kubeval on all candidates
+// Validate runs kubeval and all checks on all candidates
 func (c *Candidates) Validate() Annotations {
+	a := c.ValidateSchemas()
+	a = append(a, c.Check()...)
+	sort.Sort(a)
+	return a
+}
+
+// ValidateSchemas runs kubeval on all candidates without running the checks
+// that span resources
+func (c *Candidates) ValidateSchemas() Annotations {
 	var a Annotations
 	for _, candidate := range *c {
 		annotations := candidate.Validate()
@@ -27,6 +44,193 @@ func (c *Candidates) Validate() Annotations {
 			a = append(a, annotations...)
 		}
 	}
+	c.applySchemaSetConclusion()
 	sort.Sort(a)
 	return a
 }
+
+// allowSchemaLocations sets the schema locations the candidates may be
+// validated against
+func (c *Candidates) allowSchemaLocations(allowed func(string) bool) {
+	for _, candidate := range *c {
+		candidate.schemaLocationAllowed = allowed
+	}
+}
+
+// limit sorts the candidates by path and drops those beyond the first max,
+// returning a notice on the first dropped file stating how many weren't
+// validated. Nothing is dropped when max is 0.
+func (c *Candidates) limit(max int) *github.CheckRunAnnotation {
+	if max <= 0 || len(*c) <= max {
+		return nil
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].file.GetFilename() < (*c)[j].file.GetFilename()
+	})
+	dropped := (*c)[max:]
+	*c = (*c)[:max]
+
+	message := fmt.Sprintf("This file and %d others weren't validated", len(dropped)-1)
+	if len(dropped) == 1 {
+		message = "This file wasn't validated"
+	}
+	message = fmt.Sprintf("%s as more than maxCandidates (%d) files matched the configuration. Files are validated in order of their path.", message, max)
+
+	return &github.CheckRunAnnotation{
+		Path:            dropped[0].file.Filename,
+		BlobHRef:        dropped[0].file.BlobURL,
+		StartLine:       github.Int(1),
+		EndLine:         github.Int(1),
+		AnnotationLevel: github.String(levelNotice),
+		Title:           github.String(candidatesLimitTitle),
+		Message:         github.String(message),
+	}
+}
+
+// ValidateFailFast validates the candidates like Validate, but stops at the
+// first failure that isn't accepted by the baseline and returns only it. Every
+// file is parsed before any is validated against schemas, so that parse errors
+// are found cheaply. Checks only run when policies is set. The returned bool is
+// true when validation stopped early.
+func (c *Candidates) ValidateFailFast(policies bool, accepted baseline) (Annotations, bool) {
+	for _, candidate := range *c {
+		if candidate.bytes == nil || candidate.isHelmChart() {
+			continue
+		}
+		_, annotations := candidate.kubernetesDocuments()
+		if failure := c.firstFailure(annotations, accepted); failure != nil {
+			return Annotations{failure}, true
+		}
+	}
+
+	var a Annotations
+	for _, candidate := range *c {
+		annotations := candidate.Validate()
+		if failure := c.firstFailure(c.withoutUndecided(annotations), accepted); failure != nil {
+			return Annotations{failure}, true
+		}
+		a = append(a, annotations...)
+	}
+	c.applySchemaSetConclusion()
+	if failure := c.firstFailure(a, accepted); failure != nil {
+		return Annotations{failure}, true
+	}
+	if policies {
+		annotations := c.Check()
+		if failure := c.firstFailure(annotations, accepted); failure != nil {
+			return Annotations{failure}, true
+		}
+		a = append(a, annotations...)
+	}
+	sort.Sort(a)
+	return a, false
+}
+
+// firstFailure returns the first annotation with the failure level that isn't
+// accepted by the baseline, if any
+func (c *Candidates) firstFailure(annotations Annotations, accepted baseline) *github.CheckRunAnnotation {
+	sort.Sort(annotations)
+	for _, a := range c.suppressBaselined(annotations, accepted) {
+		if a.GetAnnotationLevel() == levelFailure {
+			return a
+		}
+	}
+	return nil
+}
+
+// withoutUndecided returns the annotations without those of schema sets, when
+// their failures may still be downgraded once every set has been validated
+func (c *Candidates) withoutUndecided(annotations Annotations) Annotations {
+	if c.schemaSetConclusion() != schemaSetConclusionAny {
+		return annotations
+	}
+	undecided := map[*github.CheckRunAnnotation]bool{}
+	for _, candidate := range *c {
+		for _, setAnnotations := range candidate.schemaSetAnnotations {
+			for _, annotation := range setAnnotations {
+				undecided[annotation] = true
+			}
+		}
+	}
+	var decided Annotations
+	for _, annotation := range annotations {
+		if !undecided[annotation] {
+			decided = append(decided, annotation)
+		}
+	}
+	return decided
+}
+
+// resourceCounts returns the number of resources of each kind contained in
+// the candidates
+func (c *Candidates) resourceCounts() (int, map[string]int) {
+	total := 0
+	counts := map[string]int{}
+	for _, r := range c.Resources() {
+		counts[r.Kind()]++
+		total++
+	}
+	return total, counts
+}
+
+// resourceCountsMarkdown returns a Markdown table of the number of resources
+// of each kind contained in the candidates
+func (c *Candidates) resourceCountsMarkdown() string {
+	total, counts := c.resourceCounts()
+	if total == 0 {
+		return ""
+	}
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	lines := []string{"| Kind | Resources |", "| --- | --- |"}
+	for _, kind := range kinds {
+		lines = append(lines, fmt.Sprintf("| %s | %d |", kind, counts[kind]))
+	}
+	lines = append(lines, fmt.Sprintf("| **Total** | **%d** |", total))
+	return strings.Join(lines, "\n")
+}
+
+// annotatedLines returns the lines of the Candidate an annotation refers to
+func (c *Candidates) annotatedLines(a *github.CheckRunAnnotation) []string {
+	for _, candidate := range *c {
+		if candidate.file.GetFilename() != a.GetPath() || candidate.bytes == nil {
+			continue
+		}
+		lines := strings.Split(string(*candidate.bytes), "\n")
+		start, end := a.GetStartLine(), a.GetEndLine()
+		if end < start {
+			end = start
+		}
+		if start < 1 || end > len(lines) {
+			return nil
+		}
+		return lines[start-1 : end]
+	}
+	return nil
+}
+
+// maxSnippetLines limits the number of lines included in a snippet
+const maxSnippetLines = 20
+
+// addSnippets appends the lines of YAML each annotation refers to to its
+// RawDetails
+func (c *Candidates) addSnippets(annotations Annotations) {
+	for _, annotation := range annotations {
+		lines := c.annotatedLines(annotation)
+		if len(lines) == 0 {
+			continue
+		}
+		if len(lines) > maxSnippetLines {
+			lines = lines[:maxSnippetLines]
+		}
+		snippet := strings.Join(lines, "\n")
+		if annotation.GetRawDetails() != "" {
+			snippet = fmt.Sprintf("%s\n%s", annotation.GetRawDetails(), snippet)
+		}
+		annotation.RawDetails = github.String(snippet)
+	}
+}
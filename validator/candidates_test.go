@@ -265,3 +265,64 @@ func TestLineNumbers(t *testing.T) {
 		return nil
 	})
 }
+
+func TestResourceCountsForMultipleDocuments(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/services/orphaned.yaml", "fixtures/deployment.yaml")
+
+	total, counts := candidates.resourceCounts()
+	if total != 4 {
+		t.Errorf("expected 4 resources, got %d", total)
+	}
+	if counts["Deployment"] != 2 || counts["Service"] != 2 {
+		t.Errorf("expected 2 Deployments and 2 Services, got %v", counts)
+	}
+
+	want := "| Kind | Resources |\n| --- | --- |\n| Deployment | 2 |\n| Service | 2 |\n| **Total** | **4** |"
+	if got := candidates.resourceCountsMarkdown(); got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+}
+
+func TestSnippetsIncludeAnnotatedLines(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/batch/jobs.yaml")
+	annotations := candidates.Check()
+	candidates.addSnippets(annotations)
+
+	lines := strings.Split(string(*candidates[0].bytes), "\n")
+	for _, annotation := range annotations {
+		want := lines[annotation.GetStartLine()-1]
+		if annotation.GetRawDetails() != want {
+			t.Errorf("expected the snippet of line %d to be %q, got %q", annotation.GetStartLine(), want, annotation.GetRawDetails())
+		}
+	}
+	if len(annotations) == 0 {
+		t.Error("expected annotations to check snippets of")
+	}
+}
+
+func TestLimitCandidates(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/deployment.yaml", "fixtures/checks/services/orphaned.yaml", "fixtures/checks/batch/jobs.yaml")
+
+	annotation := candidates.limit(2)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates to remain, got %d", len(candidates))
+	}
+	for i, want := range []string{"fixtures/checks/batch/jobs.yaml", "fixtures/checks/services/orphaned.yaml"} {
+		if got := candidates[i].file.GetFilename(); got != want {
+			t.Errorf("expected candidate %d to be %s, got %s", i, want, got)
+		}
+	}
+	if annotation == nil {
+		t.Fatal("expected a notice about the dropped candidate")
+	}
+	if annotation.GetPath() != "fixtures/deployment.yaml" || annotation.GetAnnotationLevel() != levelNotice {
+		t.Errorf("expected a notice on fixtures/deployment.yaml, got %s on %s", annotation.GetAnnotationLevel(), annotation.GetPath())
+	}
+	if want := "This file wasn't validated as more than maxCandidates (2)"; !strings.Contains(annotation.GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotation.GetMessage())
+	}
+
+	if annotation := candidates.limit(2); annotation != nil {
+		t.Errorf("expected no notice when the candidates are within the limit, got %s", annotation.GetMessage())
+	}
+}
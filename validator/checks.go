@@ -0,0 +1,89 @@
+package validator
+
+import "sort"
+
+// check inspects a Resource and returns any annotations. Every Resource being
+// validated is provided for checks that span multiple resources.
+type check func(r *Resource, resources []*Resource) Annotations
+
+// checks are run against every Resource in addition to schema validation
+var checks = []check{
+	checkOrphanedService,
+	checkDuplicateContainerNames,
+	checkAllowedNamespaces,
+	checkNamespaceLayout,
+	checkPriorityClassName,
+	checkSecretTypeKeys,
+	checkDuplicateMountPaths,
+	checkHostPortConflicts,
+	checkStorageClassAccessModes,
+	checkCronJobSchedule,
+	checkImmutableSelector,
+	checkVolumeReferences,
+	checkDuplicateEnvNames,
+	checkTerminationGracePeriod,
+	checkRequireDigest,
+	checkSelectorLabels,
+	checkDataKeys,
+	checkIngressCollisions,
+	checkMetadataKeys,
+	checkReplicasWithAutoscaler,
+	checkRollingUpdate,
+	checkZeroReplicas,
+	checkNodeLabels,
+	checkJobSpec,
+	checkMaxStorageRequest,
+	checkStorageClassName,
+	checkAllowedServiceTypes,
+	checkNodePortRange,
+	checkNetworkPolicyTypes,
+	checkDisruptionBudget,
+	checkOptionalReferences,
+	checkExportedFields,
+	checkIngressTLS,
+	checkRBACWildcards,
+	checkImagePullPolicy,
+	checkImagePolicy,
+	checkWebhookServices,
+	checkCrossNamespaceReferences,
+	checkDeprecatedAnnotations,
+	checkRecommendedLabels,
+	checkRequestsWithinLimits,
+	checkUndefinedEnvReferences,
+	checkExplicitNamespace,
+}
+
+// Resources returns the Resources parsed from all Candidates
+func (c *Candidates) Resources() []*Resource {
+	var resources []*Resource
+	for _, candidate := range *c {
+		resources = append(resources, candidate.Resources()...)
+	}
+	return resources
+}
+
+// Check runs all checks against the Resources in the Candidates
+func (c *Candidates) Check() Annotations {
+	var a Annotations
+	resources := c.Resources()
+	for _, r := range resources {
+		for _, check := range checks {
+			a = append(a, check(r, resources)...)
+		}
+	}
+	sort.Sort(a)
+	return a
+}
+
+// policies returns the policies that apply to the Resource
+func (r *Resource) policies() *KubeValidatorConfigPolicies {
+	return r.candidate.getPolicies()
+}
+
+// getPolicies returns the policies that apply to the Candidate
+func (c *Candidate) getPolicies() *KubeValidatorConfigPolicies {
+	if c.policies == nil {
+		return &KubeValidatorConfigPolicies{}
+	}
+	return c.policies
+}
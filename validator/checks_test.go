@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// fixtureCandidates returns Candidates for fixtures with the given policies
+func fixtureCandidates(t *testing.T, policies *KubeValidatorConfigPolicies, paths ...string) Candidates {
+	var candidates Candidates
+	for _, path := range paths {
+		filePath, _ := filepath.Abs(filepath.Join("..", path))
+		fileContents, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		candidate := NewCandidate(
+			&Context{
+				Event: &github.CheckSuiteEvent{},
+			}, &github.CommitFile{
+				Filename: github.String(path),
+			}, nil)
+		candidate.policies = policies
+		candidate.setBytes(&fileContents)
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// wantAnnotations compares the level and location of annotations
+func wantAnnotations(t *testing.T, annotations Annotations, want ...*github.CheckRunAnnotation) {
+	if len(annotations) != len(want) {
+		t.Errorf("a total of %d annotations were returned, wanted %d: %s", len(annotations), len(want), github.Stringify(annotations))
+		return
+	}
+	for i, annotation := range annotations {
+		if annotation.GetPath() != want[i].GetPath() || annotation.GetStartLine() != want[i].GetStartLine() || annotation.GetAnnotationLevel() != want[i].GetAnnotationLevel() {
+			t.Errorf("annotation %d: got %s:%d (%s), wanted %s:%d (%s): %s", i, annotation.GetPath(), annotation.GetStartLine(), annotation.GetAnnotationLevel(), want[i].GetPath(), want[i].GetStartLine(), want[i].GetAnnotationLevel(), annotation.GetMessage())
+		}
+	}
+}
+
+func TestResourcesFromMultipleDocuments(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/services/orphaned.yaml")
+	resources := candidates.Resources()
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(resources))
+	}
+	if resources[2].String() != "Service api" {
+		t.Errorf("expected Service api, got %s", resources[2])
+	}
+	if line := resources[2].line("spec", "ports", 0, "port"); line != 38 {
+		t.Errorf("expected port on line 38, got %d", line)
+	}
+	if line := resources[0].line("spec", "template", "spec", "containers", 0, "image"); line != 18 {
+		t.Errorf("expected image on line 18, got %d", line)
+	}
+}
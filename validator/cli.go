@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// CLI validates the Kubernetes YAML in a directory rather than the files
+// changed on a Pull Request
+type CLI struct {
+	ConfigPath string
+	Root       string
+	Format     string
+	Out        io.Writer
+}
+
+// Run validates the files under Root that match the configuration and writes
+// the resulting annotations to Out in the configured Format
+func (cli *CLI) Run() (Annotations, error) {
+	write, ok := outputFormats[cli.Format]
+	if !ok {
+		return nil, fmt.Errorf("Unknown format %s", cli.Format)
+	}
+
+	config, candidates, annotations, err := cli.validate()
+	if err != nil {
+		return nil, err
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(cli.Root, baselinePath)); err == nil {
+		annotations = candidates.suppressBaselined(annotations, parseBaselineFile(b))
+	}
+
+	if config.snippets() {
+		candidates.addSnippets(annotations)
+	}
+	annotations.truncateMessages(config.maxMessageLength())
+	return annotations, write(cli.Out, annotations)
+}
+
+// Baseline validates the files under Root like Run, and writes a
+// .kubevalidatorbaseline file accepting every finding to Out. Findings
+// already in an existing baseline are included, while those which have since
+// been fixed are dropped.
+func (cli *CLI) Baseline() error {
+	_, candidates, annotations, err := cli.validate()
+	if err != nil {
+		return err
+	}
+	return candidates.writeBaseline(cli.Out, annotations)
+}
+
+// validate loads the configuration and validates the files under Root that
+// match it
+func (cli *CLI) validate() (*KubeValidatorConfig, Candidates, Annotations, error) {
+	config, err := loadConfigFile(cli.ConfigPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if b, err := ioutil.ReadFile(filepath.Join(cli.Root, ignorePath)); err == nil {
+		config.ignore = parseIgnoreFile(b)
+	}
+
+	candidates, limited, err := cli.candidates(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var notices Annotations
+	if limited != nil {
+		notices = append(notices, limited)
+	}
+	if discovery := config.crdDiscovery(); discovery != nil {
+		crds, notice, err := cli.discoverCRDs(discovery)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if notice != nil {
+			notices = append(notices, notice)
+		}
+		candidates.setCRDs(crds)
+	}
+	// The CLI validates files its user chose to, so any source or location is
+	// allowed
+	candidates.loadImagePolicies(func(string) bool { return true })
+	candidates.allowSchemaLocations(func(string) bool { return true })
+	annotations := append(notices, candidates.Validate()...)
+	return config, candidates, annotations, nil
+}
+
+// candidates returns a Candidate for every file under Root that matches the
+// configuration, up to its maxCandidates, along with a notice when some of
+// them were dropped
+func (cli *CLI) candidates(config *KubeValidatorConfig) (Candidates, *github.CheckRunAnnotation, error) {
+	var files []*github.CommitFile
+	err := filepath.Walk(cli.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(cli.Root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &github.CommitFile{
+			Filename: github.String(filepath.ToSlash(relative)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Couldn't list files in %s", cli.Root))
+	}
+
+	var candidates Candidates = config.matchingCandidates(&Context{}, files)
+	limited := candidates.limit(config.maxCandidates())
+	for _, candidate := range candidates {
+		b, err := ioutil.ReadFile(filepath.Join(cli.Root, filepath.FromSlash(candidate.file.GetFilename())))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("Couldn't load %s", candidate.file.GetFilename()))
+		}
+		candidate.setBytes(&b)
+	}
+	return candidates, limited, nil
+}
+
+func loadConfigFile(path string) (*KubeValidatorConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Couldn't load %s", path))
+	}
+	config := &KubeValidatorConfig{}
+	if err := yaml.Unmarshal(b, config); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Couldn't parse %s", path))
+	}
+	if !config.Valid() {
+		return nil, fmt.Errorf("%s is invalid", path)
+	}
+	return config, nil
+}
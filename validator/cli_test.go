@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCLISnapshot(t *testing.T) {
+	var out bytes.Buffer
+	cli := &CLI{
+		ConfigPath: "../fixtures/cli/kubevalidator.yaml",
+		Root:       "../fixtures",
+		Format:     "snapshot",
+		Out:        &out,
+	}
+	annotations, err := cli.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !annotations.Failed() {
+		t.Error("expected validation to fail")
+	}
+
+	want := "checks/helm/missing-version/Chart.yaml\t1-1\tInvalid Helm chart\tfailure\tversion is required\n" +
+		"checks/helm/missing-version/Chart.yaml\t4-4\tInvalid Helm chart\tfailure\ttype must be one of application, library, not service\n" +
+		"checks/helm/missing-version/Chart.yaml\t8-8\tInvalid Helm chart\tfailure\tdependencies[0].repository must be a URL or a repository alias, not charts.example.com\n" +
+		"checks/helm/numeric-version/Chart.yaml\t3-3\tInvalid Helm chart\tfailure\tversion must be a SemVer 2 version, not a number. Quote it so that it's read as a string.\n" +
+		"checks/helm/numeric-version/Chart.yaml\t6-6\tInvalid Helm chart\tfailure\tdependencies[0].version must be a SemVer version or range, not latest\n" +
+		"checks/helm/numeric-version/Chart.yaml\t9-9\tInvalid Helm chart\tfailure\tdependencies[1].version must be a SemVer version or range, not a number. Quote it so that it's read as a string.\n"
+	if out.String() != want {
+		t.Errorf("snapshot was\n%s\nwanted\n%s", out.String(), want)
+	}
+}
+
+func TestCLIUnknownFormat(t *testing.T) {
+	cli := &CLI{
+		ConfigPath: "../fixtures/cli/kubevalidator.yaml",
+		Root:       "../fixtures",
+		Format:     "unknown",
+	}
+	if _, err := cli.Run(); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
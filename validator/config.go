@@ -2,10 +2,13 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
+	"reflect"
 	"regexp"
+	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/bmatcuk/doublestar"
+	"github.com/google/go-github/github"
 )
 
 // KubeValidatorConfig maps globs of Kubernetes config to schemas which validate
@@ -14,38 +17,507 @@ type KubeValidatorConfig struct {
 	APIVersion string                   `yaml:"apiversion"`
 	Kind       string                   `yaml:"kind"`
 	Spec       *KubeValidatorConfigSpec `yaml:"spec"`
+
+	ignore   ignoreFile
+	baseline baseline
+
+	// schemasOnly is set when policies were skipped outside of the policy
+	// window
+	schemasOnly bool
+
+	// stoppedEarly is set when failFast stopped validation at the first error
+	stoppedEarly bool
+
+	// timings are reported in the check run summary when Debug is set
+	timings *timings
 }
 
-// KubeValidatorConfigSpec contains a list of manifests
+// KubeValidatorConfigSpec contains a list of manifests and the policies that
+// apply to them by default
 type KubeValidatorConfigSpec struct {
 	Manifests []*KubeValidatorConfigManifest `yaml:"manifests"`
+	Policies  *KubeValidatorConfigPolicies   `yaml:"policies,omitempty"`
+	Profiles  []*KubeValidatorConfigProfile  `yaml:"profiles,omitempty"`
+
+	// MaxMessageLength truncates longer annotation messages when set
+	MaxMessageLength int `yaml:"maxMessageLength,omitempty"`
+
+	// MaxSummaryLength moves longer check run summaries to a secret Gist when
+	// set
+	MaxSummaryLength int `yaml:"maxSummaryLength,omitempty"`
+
+	// MaxCandidates limits the number of files validated, in order of their
+	// path, when set
+	MaxCandidates int `yaml:"maxCandidates,omitempty"`
+
+	// FailFast stops validation at the first error, which is the only one
+	// reported
+	FailFast bool `yaml:"failFast,omitempty"`
+
+	// PolicyWindow restricts policies to a daily window when set. Outside of
+	// it only schemas are validated.
+	PolicyWindow *KubeValidatorConfigWindow `yaml:"policyWindow,omitempty"`
+
+	// DiscoverCRDs validates custom resources against the
+	// CustomResourceDefinitions in files matching its globs anywhere in the
+	// repository, including those not changed on a Pull Request
+	DiscoverCRDs *KubeValidatorConfigCRDDiscovery `yaml:"discoverCRDs,omitempty"`
+
+	// Snippets includes the lines of YAML each annotation refers to in its
+	// raw details
+	Snippets bool `yaml:"snippets,omitempty"`
+
+	// Debug appends the time spent in each stage of validation to the check
+	// run summary
+	Debug bool `yaml:"debug,omitempty"`
+
+	// SchemaSets may be referenced by name from manifests. The check fails
+	// when any set fails unless SchemaSetConclusion is "any", in which case
+	// only one set needs to pass.
+	SchemaSets          []*KubeValidatorConfigSchemaSet `yaml:"schemaSets,omitempty"`
+	SchemaSetConclusion string                          `yaml:"schemaSetConclusion,omitempty"`
+}
+
+// KubeValidatorConfigWindow is a daily window from Start until End, both in
+// the 15:04 format, in TimeZone (UTC by default). Windows ending before they
+// start span midnight.
+type KubeValidatorConfigWindow struct {
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	TimeZone string `yaml:"timeZone,omitempty"`
+}
+
+// KubeValidatorConfigCRDDiscovery contains globs matching the files to search
+// for CustomResourceDefinitions. At most MaxFiles files, 100 by default, are
+// searched.
+type KubeValidatorConfigCRDDiscovery struct {
+	Globs    []string `yaml:"globs"`
+	MaxFiles int      `yaml:"maxFiles,omitempty"`
+}
+
+// KubeValidatorConfigSchemaSet is a named bundle of schemas. Manifests
+// referencing a set are validated against every schema in it, and the check
+// run summary reports whether each set passed.
+type KubeValidatorConfigSchemaSet struct {
+	Name    string                       `yaml:"name"`
+	Schemas []*KubeValidatorConfigSchema `yaml:"schemas"`
+}
+
+// Schema set conclusions
+const (
+	schemaSetConclusionAll = "all"
+	schemaSetConclusionAny = "any"
+)
+
+// KubeValidatorConfigProfile replaces the manifests and policies of the spec
+// for Pull Requests whose base branch matches one of Branches. Manifests and
+// policies that aren't set are inherited from the spec.
+type KubeValidatorConfigProfile struct {
+	Name      string                         `yaml:"name"`
+	Branches  []string                       `yaml:"branches"`
+	Manifests []*KubeValidatorConfigManifest `yaml:"manifests,omitempty"`
+	Policies  *KubeValidatorConfigPolicies   `yaml:"policies,omitempty"`
 }
 
-// KubeValidatorConfigManifest contains a glob and a list of schema
+// KubeValidatorConfigManifest contains a glob and a list of schema. Policies
+// replace those of the spec for matching files.
 type KubeValidatorConfigManifest struct {
-	Glob    string                       `yaml:"glob"`
-	Schemas []*KubeValidatorConfigSchema `yaml:"schemas,omitempty"`
+	Glob       string                       `yaml:"glob"`
+	Schemas    []*KubeValidatorConfigSchema `yaml:"schemas,omitempty"`
+	SchemaSets []string                     `yaml:"schemaSets,omitempty"`
+	Policies   *KubeValidatorConfigPolicies `yaml:"policies,omitempty"`
 }
 
 // KubeValidatorConfigSchema contains options for kubeval
 type KubeValidatorConfigSchema struct {
 	Name       string `yaml:"name,omitempty"`
 	SchemaFork string `yaml:"schemaFork,omitempty"`
+	Location   string `yaml:"location,omitempty"`
 
 	Version     string `yaml:"version,omitempty"`
 	ConfigType  string `yaml:"type,omitempty"`
 	LineNumbers bool   `yaml:"lineNumbers,omitempty"`
+
+	// Gateway API resources are validated against the CustomResourceDefinitions
+	// of this Gateway API release when set
+	GatewayAPIVersion  string `yaml:"gatewayAPIVersion,omitempty"`
+	GatewayAPIChannel  string `yaml:"gatewayAPIChannel,omitempty"`
+	GatewayAPILocation string `yaml:"gatewayAPILocation,omitempty"`
+}
+
+// KubeValidatorConfigPolicies configures the checks run in addition to schema
+// validation
+type KubeValidatorConfigPolicies struct {
+	OrphanedServices        *KubeValidatorConfigRule `yaml:"orphanedServices,omitempty"`
+	DuplicateContainerNames *KubeValidatorConfigRule `yaml:"duplicateContainerNames,omitempty"`
+	SecretTypeKeys          *KubeValidatorConfigRule `yaml:"secretTypeKeys,omitempty"`
+	DataKeys                *KubeValidatorConfigRule `yaml:"dataKeys,omitempty"`
+	MetadataKeys            *KubeValidatorConfigRule `yaml:"metadataKeys,omitempty"`
+	RollingUpdates          *KubeValidatorConfigRule `yaml:"rollingUpdates,omitempty"`
+	DuplicateMountPaths     *KubeValidatorConfigRule `yaml:"duplicateMountPaths,omitempty"`
+	DuplicateEnvNames       *KubeValidatorConfigRule `yaml:"duplicateEnvNames,omitempty"`
+	HostPortConflicts       *KubeValidatorConfigRule `yaml:"hostPortConflicts,omitempty"`
+	IngressCollisions       *KubeValidatorConfigRule `yaml:"ingressCollisions,omitempty"`
+	CronJobSchedules        *KubeValidatorConfigRule `yaml:"cronJobSchedules,omitempty"`
+	ImmutableSelectors      *KubeValidatorConfigRule `yaml:"immutableSelectors,omitempty"`
+	SelectorLabels          *KubeValidatorConfigRule `yaml:"selectorLabels,omitempty"`
+	NonResources            *KubeValidatorConfigRule `yaml:"nonResources,omitempty"`
+	MalformedDocuments      *KubeValidatorConfigRule `yaml:"malformedDocuments,omitempty"`
+	UndefinedEnvReferences  *KubeValidatorConfigRule `yaml:"undefinedEnvReferences,omitempty"`
+	RequestsWithinLimits    *KubeValidatorConfigRule `yaml:"requestsWithinLimits,omitempty"`
+	Whitespace              *KubeValidatorConfigRule `yaml:"whitespace,omitempty"`
+	ExportedFields          *KubeValidatorConfigRule `yaml:"exportedFields,omitempty"`
+	RequireDigest           *KubeValidatorConfigRule `yaml:"requireDigest,omitempty"`
+	NetworkPolicyTypes      *KubeValidatorConfigRule `yaml:"networkPolicyTypes,omitempty"`
+
+	// RequireExplicitNamespace fails when a namespaced resource doesn't set
+	// metadata.namespace, for GitOps tools that would otherwise apply it to
+	// whichever namespace they're configured with
+	RequireExplicitNamespace *KubeValidatorConfigSwitch `yaml:"requireExplicitNamespace,omitempty"`
+
+	// RequireStorageClass fails when a PersistentVolumeClaim or a StatefulSet's
+	// volumeClaimTemplate doesn't set storageClassName
+	RequireStorageClass *KubeValidatorConfigSwitch `yaml:"requireStorageClass,omitempty"`
+
+	AllowedNamespaces  *KubeValidatorConfigAllowedNamespaces  `yaml:"allowedNamespaces,omitempty"`
+	NamespaceLayout    *KubeValidatorConfigNamespaceLayout    `yaml:"namespaceLayout,omitempty"`
+	PriorityClassNames *KubeValidatorConfigPriorityClassNames `yaml:"priorityClassNames,omitempty"`
+
+	StorageClassAccessModes  *KubeValidatorConfigStorageClassAccessModes  `yaml:"storageClassAccessModes,omitempty"`
+	VolumeReferences         *KubeValidatorConfigReferences               `yaml:"volumeReferences,omitempty"`
+	OptionalReferences       *KubeValidatorConfigReferences               `yaml:"optionalReferences,omitempty"`
+	TerminationGracePeriods  *KubeValidatorConfigTerminationGracePeriods  `yaml:"terminationGracePeriods,omitempty"`
+	NodeLabels               *KubeValidatorConfigNodeLabels               `yaml:"nodeLabels,omitempty"`
+	JobSpecs                 *KubeValidatorConfigJobSpecs                 `yaml:"jobSpecs,omitempty"`
+	MaxStorageRequests       *KubeValidatorConfigMaxStorageRequests       `yaml:"maxStorageRequests,omitempty"`
+	AllowedServiceTypes      *KubeValidatorConfigAllowedServiceTypes      `yaml:"allowedServiceTypes,omitempty"`
+	ImagePullPolicies        *KubeValidatorConfigImagePullPolicies        `yaml:"imagePullPolicies,omitempty"`
+	ImagePolicy              *KubeValidatorConfigImagePolicy              `yaml:"imagePolicy,omitempty"`
+	WebhookServices          *KubeValidatorConfigWebhookServices          `yaml:"webhookServices,omitempty"`
+	DeprecatedAnnotations    *KubeValidatorConfigDeprecatedAnnotations    `yaml:"deprecatedAnnotations,omitempty"`
+	RecommendedLabels        *KubeValidatorConfigRecommendedLabels        `yaml:"recommendedLabels,omitempty"`
+	NodePorts                *KubeValidatorConfigNodePorts                `yaml:"nodePorts,omitempty"`
+	DisruptionBudgets        *KubeValidatorConfigDisruptionBudgets        `yaml:"disruptionBudgets,omitempty"`
+	ZeroReplicas             *KubeValidatorConfigZeroReplicas             `yaml:"zeroReplicas,omitempty"`
+	CrossNamespaceReferences *KubeValidatorConfigCrossNamespaceReferences `yaml:"crossNamespaceReferences,omitempty"`
+	IngressTLS               *KubeValidatorConfigIngressTLS               `yaml:"ingressTLS,omitempty"`
+	RBACWildcards            *KubeValidatorConfigRBACWildcards            `yaml:"rbacWildcards,omitempty"`
+	ReplicasWithAutoscaler   *KubeValidatorConfigReplicasWithAutoscaler   `yaml:"replicasWithAutoscaler,omitempty"`
+}
+
+// KubeValidatorConfigRule contains options common to all policies. Level is
+// one of notice, warning, failure or off. Rules with an EnforceAfter date
+// produce warnings until that date (UTC) and failures from then on, and can't
+// also set a Level.
+type KubeValidatorConfigRule struct {
+	Level        string `yaml:"level,omitempty"`
+	EnforceAfter string `yaml:"enforceAfter,omitempty"`
+}
+
+// KubeValidatorConfigSwitch is a rule without options, which can also be
+// enabled with true or turned off with false
+type KubeValidatorConfigSwitch struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+}
+
+// UnmarshalYAML parses either a boolean or a rule
+func (s *KubeValidatorConfigSwitch) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			s.Level = levelOff
+		}
+		return nil
+	}
+	return unmarshal(&s.KubeValidatorConfigRule)
+}
+
+const (
+	levelOff   = "off"
+	dateLayout = "2006-01-02"
+)
+
+// now returns the current time. Tests replace it to control when rules are
+// enforced.
+var now = time.Now
+
+// KubeValidatorConfigAllowedNamespaces contains globs matching the namespaces
+// resources may target. Resources without a namespace are assumed to target
+// DefaultNamespace, and are allowed when it's empty.
+type KubeValidatorConfigAllowedNamespaces struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Namespaces              []string `yaml:"namespaces"`
+	DefaultNamespace        string   `yaml:"defaultNamespace,omitempty"`
+}
+
+// KubeValidatorConfigNamespaceLayout contains regular expressions matching the
+// paths of files whose first capture group is the namespace their resources
+// must target
+type KubeValidatorConfigNamespaceLayout struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Patterns                []string `yaml:"patterns"`
+}
+
+// KubeValidatorConfigPriorityClassNames requires workloads of Kinds, or all
+// workloads when empty, to use one of the Allowed priority classes.
+type KubeValidatorConfigPriorityClassNames struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Kinds                   []string `yaml:"kinds,omitempty"`
+	Allowed                 []string `yaml:"allowed"`
+}
+
+// KubeValidatorConfigStorageClassAccessModes maps the names of storage classes
+// to the access modes they support
+type KubeValidatorConfigStorageClassAccessModes struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	StorageClasses          map[string][]string `yaml:"storageClasses"`
+}
+
+// KubeValidatorConfigReferences contains globs matching the names of
+// ConfigMaps and Secrets known to exist outside of the Pull Request
+type KubeValidatorConfigReferences struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	ConfigMaps              []string `yaml:"configMaps,omitempty"`
+	Secrets                 []string `yaml:"secrets,omitempty"`
+}
+
+// KubeValidatorConfigTerminationGracePeriods contains the minimum
+// terminationGracePeriodSeconds of pods
+type KubeValidatorConfigTerminationGracePeriods struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	MinimumSeconds          int `yaml:"minimumSeconds,omitempty"`
+}
+
+// KubeValidatorConfigAllowedServiceTypes contains the types Services may use.
+// The types of the first entry of Namespaces with a glob matching the
+// namespace of a Service replace Types.
+type KubeValidatorConfigAllowedServiceTypes struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Types                   []string                             `yaml:"types"`
+	Namespaces              []*KubeValidatorConfigNamespaceTypes `yaml:"namespaces,omitempty"`
+}
+
+// KubeValidatorConfigNamespaceTypes contains the Service types allowed in
+// namespaces matching any of Namespaces
+type KubeValidatorConfigNamespaceTypes struct {
+	Namespaces []string `yaml:"namespaces"`
+	Types      []string `yaml:"types"`
+}
+
+// KubeValidatorConfigImagePullPolicies contains the imagePullPolicy required
+// for images pinned to a tag or digest, and for images using the latest tag.
+// Any policy is allowed when unset, as long as one is set explicitly.
+type KubeValidatorConfigImagePullPolicies struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Pinned                  string `yaml:"pinned,omitempty"`
+	Latest                  string `yaml:"latest,omitempty"`
+}
+
+// KubeValidatorConfigWebhookServices contains globs matching the
+// namespace/name of Services known to exist outside of the Pull Request
+type KubeValidatorConfigWebhookServices struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Services                []string `yaml:"services,omitempty"`
+}
+
+// KubeValidatorConfigDisruptionBudgets contains the kinds of workloads that
+// require a PodDisruptionBudget, Deployments by default, and globs matching the
+// namespace/name of workloads whose budget exists outside of the Pull Request
+type KubeValidatorConfigDisruptionBudgets struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Kinds                   []string `yaml:"kinds,omitempty"`
+	Workloads               []string `yaml:"workloads,omitempty"`
+}
+
+// KubeValidatorConfigZeroReplicas contains globs matching the namespace/name
+// of workloads which may be scaled to zero replicas
+type KubeValidatorConfigZeroReplicas struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Workloads               []string `yaml:"workloads,omitempty"`
+}
+
+// KubeValidatorConfigCrossNamespaceReferences contains globs matching the
+// from/to namespaces of references allowed to cross namespaces
+type KubeValidatorConfigCrossNamespaceReferences struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Allowed                 []string `yaml:"allowed,omitempty"`
+}
+
+// KubeValidatorConfigIngressTLS contains whether Ingresses must configure TLS,
+// and the names of the cert-manager issuers they may request certificates
+// from. Any issuer is allowed when Issuers is empty.
+type KubeValidatorConfigIngressTLS struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Required                bool     `yaml:"required,omitempty"`
+	Issuers                 []string `yaml:"issuers,omitempty"`
+}
+
+// KubeValidatorConfigRBACWildcards contains the fields of RBAC rules which
+// may use a wildcard
+type KubeValidatorConfigRBACWildcards struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	AllowAPIGroups          bool `yaml:"allowAPIGroups,omitempty"`
+	AllowResources          bool `yaml:"allowResources,omitempty"`
+	AllowVerbs              bool `yaml:"allowVerbs,omitempty"`
+}
+
+// KubeValidatorConfigReplicasWithAutoscaler contains globs matching the keys
+// of annotations with which autoscalers other than HorizontalPodAutoscalers
+// manage the replicas of a workload
+type KubeValidatorConfigReplicasWithAutoscaler struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Annotations             []string `yaml:"annotations,omitempty"`
+}
+
+// KubeValidatorConfigDeprecatedAnnotations maps deprecated annotation keys to
+// guidance on what replaces them
+type KubeValidatorConfigDeprecatedAnnotations struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Annotations             map[string]string `yaml:"annotations"`
+}
+
+// KubeValidatorConfigImagePolicy contains the location of a file listing the
+// images containers may use, either an http(s) URL or a local path
+type KubeValidatorConfigImagePolicy struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Source                  string `yaml:"source"`
+}
+
+// KubeValidatorConfigRecommendedLabels lists the recommended labels, without
+// their app.kubernetes.io/ prefix, that workloads must carry. All of them are
+// required if omitted.
+type KubeValidatorConfigRecommendedLabels struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Labels                  []string `yaml:"labels,omitempty"`
+}
+
+// KubeValidatorConfigMaxStorageRequests contains the maximum quantity of
+// storage, e.g. 100Gi, that claims may request
+type KubeValidatorConfigMaxStorageRequests struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Maximum                 string `yaml:"maximum"`
+}
+
+// KubeValidatorConfigNodeLabels contains globs matching custom node labels
+// that workloads may schedule pods with, in addition to well known ones
+type KubeValidatorConfigNodeLabels struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Allowed                 []string `yaml:"allowed,omitempty"`
+}
+
+// KubeValidatorConfigJobSpecs contains the maximum backoffLimit of Jobs and
+// CronJobs. The backoffLimit isn't limited when MaxBackoffLimit is zero.
+type KubeValidatorConfigJobSpecs struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	MaxBackoffLimit         int `yaml:"maxBackoffLimit,omitempty"`
+}
+
+// maxBackoffLimit returns the configured maximum backoffLimit, or zero when
+// unset
+func (j *KubeValidatorConfigJobSpecs) maxBackoffLimit() int {
+	if j == nil {
+		return 0
+	}
+	return j.MaxBackoffLimit
+}
+
+// Kubernetes' default --service-node-port-range
+const (
+	defaultMinNodePort = 30000
+	defaultMaxNodePort = 32767
+)
+
+// KubeValidatorConfigNodePorts contains the range of ports Services may use as
+// a nodePort, which defaults to 30000-32767
+type KubeValidatorConfigNodePorts struct {
+	KubeValidatorConfigRule `yaml:",inline"`
+	Min                     int `yaml:"min,omitempty"`
+	Max                     int `yaml:"max,omitempty"`
+}
+
+// portRange returns the configured range of node ports, falling back to the
+// defaults of Kubernetes
+func (n *KubeValidatorConfigNodePorts) portRange() (int, int) {
+	min, max := defaultMinNodePort, defaultMaxNodePort
+	if n != nil && n.Min != 0 {
+		min = n.Min
+	}
+	if n != nil && n.Max != 0 {
+		max = n.Max
+	}
+	return min, max
+}
+
+// ruleConfig is implemented by KubeValidatorConfigRule and every struct that
+// embeds it
+type ruleConfig interface {
+	configRule() *KubeValidatorConfigRule
+}
+
+func (rule *KubeValidatorConfigRule) configRule() *KubeValidatorConfigRule {
+	return rule
+}
+
+// level returns the annotation level of the rule, falling back to defaultLevel
+// when unset. An empty string is returned when the rule is turned off.
+func (rule *KubeValidatorConfigRule) level(defaultLevel string) string {
+	if rule == nil || (rule.Level == "" && rule.EnforceAfter == "") {
+		return defaultLevel
+	}
+	if rule.Level == levelOff {
+		return ""
+	}
+	if enforceAfter, err := time.Parse(dateLayout, rule.EnforceAfter); err == nil {
+		if now().Before(enforceAfter) {
+			return levelWarning
+		}
+		return levelFailure
+	}
+	return rule.Level
+}
+
+// rules returns every configured rule
+func (policies *KubeValidatorConfigPolicies) rules() []*KubeValidatorConfigRule {
+	var rules []*KubeValidatorConfigRule
+	if policies == nil {
+		return rules
+	}
+	v := reflect.ValueOf(policies).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Ptr || field.IsNil() {
+			continue
+		}
+		if rule, ok := field.Interface().(ruleConfig); ok {
+			rules = append(rules, rule.configRule())
+		}
+	}
+	return rules
 }
 
 func (config *KubeValidatorConfig) matchingCandidates(context *Context, files []*github.CommitFile) []*Candidate {
 	var candidates []*Candidate
 
 	for _, file := range files {
+		if config.ignore.ignored(file.GetFilename()) {
+			continue
+		}
 		if config.Spec != nil {
 			spec := *config.Spec
 			for _, manifestConfig := range spec.Manifests {
 				if matched, _ := doublestar.Match(manifestConfig.Glob, file.GetFilename()); matched {
 					candidate := NewCandidate(context, file, manifestConfig.Schemas)
+					if len(manifestConfig.SchemaSets) > 0 {
+						if len(manifestConfig.Schemas) == 0 {
+							candidate.schemas = nil
+						}
+						candidate.schemaSets = spec.schemaSets(manifestConfig.SchemaSets)
+						candidate.schemaSetConclusion = spec.SchemaSetConclusion
+					}
+					candidate.policies = spec.Policies
+					if manifestConfig.Policies != nil {
+						candidate.policies = manifestConfig.Policies
+					}
 					candidates = append(candidates, candidate)
 				}
 			}
@@ -55,28 +527,245 @@ func (config *KubeValidatorConfig) matchingCandidates(context *Context, files []
 	return candidates
 }
 
+// schemaSets returns the schema sets with names
+func (spec *KubeValidatorConfigSpec) schemaSets(names []string) []*KubeValidatorConfigSchemaSet {
+	var sets []*KubeValidatorConfigSchemaSet
+	for _, set := range spec.SchemaSets {
+		if containsString(names, set.Name) {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+// maxMessageLength returns the length after which annotation messages are
+// truncated, or 0 if they shouldn't be
+func (config *KubeValidatorConfig) maxMessageLength() int {
+	if config.Spec == nil {
+		return 0
+	}
+	return config.Spec.MaxMessageLength
+}
+
+// maxCandidates returns the number of files that are validated, or 0 if it
+// isn't limited
+func (config *KubeValidatorConfig) maxCandidates() int {
+	if config.Spec == nil {
+		return 0
+	}
+	return config.Spec.MaxCandidates
+}
+
+// crdDiscovery returns the configuration of CRD discovery, or nil if
+// CustomResourceDefinitions shouldn't be discovered
+func (config *KubeValidatorConfig) crdDiscovery() *KubeValidatorConfigCRDDiscovery {
+	if config.Spec == nil || config.Spec.DiscoverCRDs == nil || len(config.Spec.DiscoverCRDs.Globs) == 0 {
+		return nil
+	}
+	return config.Spec.DiscoverCRDs
+}
+
+// failFast returns true when validation should stop at the first error
+func (config *KubeValidatorConfig) failFast() bool {
+	return config.Spec != nil && config.Spec.FailFast
+}
+
+// debug returns true when the check run summary should include timings
+func (config *KubeValidatorConfig) debug() bool {
+	return config.Spec != nil && config.Spec.Debug
+}
+
+// snippets returns true when annotations should include the lines of YAML
+// they refer to
+func (config *KubeValidatorConfig) snippets() bool {
+	return config.Spec != nil && config.Spec.Snippets
+}
+
+// maxSummaryLength returns the length after which check run summaries are
+// uploaded to a Gist, or 0 if they shouldn't be
+func (config *KubeValidatorConfig) maxSummaryLength() int {
+	if config.Spec == nil {
+		return 0
+	}
+	return config.Spec.MaxSummaryLength
+}
+
+// forBranch returns the configuration of the first profile matching the base
+// branch of a Pull Request, or the configuration itself when none match.
+func (config *KubeValidatorConfig) forBranch(branch string) *KubeValidatorConfig {
+	if config.Spec == nil || branch == "" {
+		return config
+	}
+	for _, profile := range config.Spec.Profiles {
+		for _, glob := range profile.Branches {
+			if matched, _ := doublestar.Match(glob, branch); !matched {
+				continue
+			}
+			spec := *config.Spec
+			spec.Profiles = nil
+			if len(profile.Manifests) > 0 {
+				spec.Manifests = profile.Manifests
+			}
+			if profile.Policies != nil {
+				spec.Policies = profile.Policies
+			}
+			return &KubeValidatorConfig{
+				APIVersion: config.APIVersion,
+				Kind:       config.Kind,
+				Spec:       &spec,
+			}
+		}
+	}
+	return config
+}
+
 // Valid returns a boolean indicatating whether or not the config is well formed
 // TODO replace me with an actual schema
 func (config *KubeValidatorConfig) Valid() bool {
 	re := regexp.MustCompile(`(?mi)^[a-z][a-z\-]{0,38}$`)
 	if config.Spec != nil {
 		spec := *config.Spec
-		for _, manifest := range spec.Manifests {
-			for _, schema := range manifest.Schemas {
-				if schema.SchemaFork != "" && !re.MatchString(schema.SchemaFork) {
-					return false
-				}
+		if spec.PolicyWindow != nil && !spec.PolicyWindow.valid() {
+			return false
+		}
+		if spec.MaxMessageLength < 0 || spec.MaxSummaryLength < 0 || spec.MaxCandidates < 0 || (spec.DiscoverCRDs != nil && spec.DiscoverCRDs.MaxFiles < 0) || !spec.Policies.valid() || !manifestsValid(spec.Manifests, re) || !spec.schemaSetsValid(spec.Manifests) {
+			return false
+		}
+		for _, profile := range spec.Profiles {
+			if !profile.Policies.valid() || !manifestsValid(profile.Manifests, re) || !spec.schemaSetsValid(profile.Manifests) {
+				return false
 			}
 		}
 	}
 	return true
 }
 
-// SchemaLocation composes SchemaFork with a base url
+func manifestsValid(manifests []*KubeValidatorConfigManifest, schemaForkPattern *regexp.Regexp) bool {
+	for _, manifest := range manifests {
+		for _, schema := range manifest.Schemas {
+			if !schema.valid(schemaForkPattern) {
+				return false
+			}
+		}
+		if !manifest.Policies.valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// valid returns false when the schema fork isn't a GitHub account name, the
+// location isn't an https or file URL, or the Gateway API location isn't an
+// https URL
+func (schema *KubeValidatorConfigSchema) valid(schemaForkPattern *regexp.Regexp) bool {
+	if schema.SchemaFork != "" && !schemaForkPattern.MatchString(schema.SchemaFork) {
+		return false
+	}
+	if schema.Location != "" {
+		if location, err := url.Parse(schema.Location); err != nil || (location.Scheme != "https" && location.Scheme != "file") {
+			return false
+		}
+	}
+	if schema.GatewayAPILocation != "" {
+		if location, err := url.Parse(schema.GatewayAPILocation); err != nil || location.Scheme != "https" {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaSetsValid returns false when manifests reference schema sets that
+// aren't defined, their schemas are invalid, or the schema set conclusion is
+// unknown
+func (spec *KubeValidatorConfigSpec) schemaSetsValid(manifests []*KubeValidatorConfigManifest) bool {
+	switch spec.SchemaSetConclusion {
+	case "", schemaSetConclusionAll, schemaSetConclusionAny:
+	default:
+		return false
+	}
+	re := regexp.MustCompile(`(?mi)^[a-z][a-z\-]{0,38}$`)
+	for _, set := range spec.SchemaSets {
+		for _, schema := range set.Schemas {
+			if !schema.valid(re) {
+				return false
+			}
+		}
+	}
+	for _, manifest := range manifests {
+		if len(spec.schemaSets(manifest.SchemaSets)) != len(manifest.SchemaSets) {
+			return false
+		}
+	}
+	return true
+}
+
+func (policies *KubeValidatorConfigPolicies) valid() bool {
+	for _, rule := range policies.rules() {
+		switch rule.Level {
+		case "", levelOff, levelNotice, levelWarning, levelFailure:
+		default:
+			return false
+		}
+		if rule.EnforceAfter != "" {
+			if _, err := time.Parse(dateLayout, rule.EnforceAfter); err != nil || rule.Level != "" {
+				return false
+			}
+		}
+	}
+	if policies != nil && policies.MaxStorageRequests != nil {
+		if _, err := parseQuantity(policies.MaxStorageRequests.Maximum); err != nil {
+			return false
+		}
+	}
+	if policies != nil && policies.ImagePolicy != nil && policies.ImagePolicy.Source == "" {
+		return false
+	}
+	if policies != nil && policies.NamespaceLayout != nil {
+		for _, pattern := range policies.NamespaceLayout.Patterns {
+			if re, err := regexp.Compile(pattern); err != nil || re.NumSubexp() < 1 {
+				return false
+			}
+		}
+	}
+	if policies != nil && policies.NodePorts != nil {
+		if min, max := policies.NodePorts.portRange(); min < 1 || max > 65535 || min > max {
+			return false
+		}
+	}
+	return true
+}
+
+// name returns the name of the schema used in annotations
+func (schema *KubeValidatorConfigSchema) name() string {
+	if schema.Name != "" {
+		return schema.Name
+	} else if schema.Version != "" {
+		return schema.Version
+	}
+	return "default"
+}
+
+// SchemaLocation composes SchemaFork with a base url, unless Location is set
 func (schema *KubeValidatorConfigSchema) SchemaLocation() string {
+	if schema.Location != "" {
+		return schema.Location
+	}
 	schemaFork := schema.SchemaFork
 	if schemaFork == "" {
 		schemaFork = "garethr"
 	}
 	return fmt.Sprintf("https://raw.githubusercontent.com/%s", schemaFork)
 }
+
+// schemaLocationAllowed returns true when location matches one of the globs of
+// schema locations the operator of the GitHub App allows repositories to use.
+// No locations are allowed by default, as configurations could otherwise read
+// files on, or make requests from, the server.
+func (c *Context) schemaLocationAllowed(location string) bool {
+	for _, glob := range c.SchemaLocations {
+		if matched, _ := doublestar.Match(glob, location); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/github"
 	yaml "gopkg.in/yaml.v2"
@@ -66,3 +67,85 @@ func TestInvalidConfigIsNotValid(t *testing.T) {
 		return
 	}
 }
+
+func TestProfileSelectedByBaseBranch(t *testing.T) {
+	filePath, _ := filepath.Abs("../fixtures/kubevalidator-profiles.yaml")
+	fileContents, _ := ioutil.ReadFile(filePath)
+	config := &KubeValidatorConfig{}
+	err := yaml.Unmarshal(fileContents, config)
+	if err != nil {
+		t.Errorf("Unmarshaling kubevalidator-profiles.yaml failed with %v", err)
+		return
+	}
+	if !config.Valid() {
+		t.Errorf("Config expected to be valid: %+v", config)
+		return
+	}
+
+	levels := map[string]string{
+		"release/1.0": "failure",
+		"main":        "notice",
+	}
+	for branch, level := range levels {
+		e := &github.CheckSuiteEvent{
+			CheckSuite: &github.CheckSuite{
+				PullRequests: []*github.PullRequest{{
+					Base: &github.PullRequestBranch{
+						Ref: github.String(branch),
+					},
+				}},
+			},
+		}
+		files := []*github.CommitFile{{
+			Filename: github.String("fixtures/deployment.yaml"),
+		}}
+		candidates := config.forBranch(baseBranch(e)).matchingCandidates(&Context{}, files)
+		if len(candidates) != 1 {
+			t.Errorf("%s: expected 1 match, got %d", branch, len(candidates))
+			continue
+		}
+		if got := candidates[0].policies.OrphanedServices.Level; got != level {
+			t.Errorf("%s: expected the %s level, got %s", branch, level, got)
+		}
+	}
+}
+
+func TestEnforceAfterEscalatesRules(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+
+	policies := &KubeValidatorConfigPolicies{
+		DuplicateEnvNames: &KubeValidatorConfigRule{EnforceAfter: "2026-10-01"},
+	}
+	candidates := fixtureCandidates(t, policies, "fixtures/checks/containers/duplicate-env.yaml")
+	annotations := candidates.Check()
+	if len(annotations) == 0 {
+		t.Fatal("expected annotations")
+	}
+	for _, annotation := range annotations {
+		if annotation.GetAnnotationLevel() != levelFailure {
+			t.Errorf("expected a failure after the enforcement date, got %s", annotation.GetAnnotationLevel())
+		}
+	}
+
+	policies.DuplicateEnvNames.EnforceAfter = "2026-11-01"
+	candidates = fixtureCandidates(t, policies, "fixtures/checks/containers/duplicate-env.yaml")
+	for _, annotation := range candidates.Check() {
+		if annotation.GetAnnotationLevel() != levelWarning {
+			t.Errorf("expected a warning before the enforcement date, got %s", annotation.GetAnnotationLevel())
+		}
+	}
+
+	if !policies.valid() {
+		t.Error("expected a valid enforceAfter date to be valid")
+	}
+	policies.DuplicateEnvNames.EnforceAfter = "next week"
+	if policies.valid() {
+		t.Error("expected an invalid enforceAfter date to be invalid")
+	}
+
+	policies.DuplicateEnvNames = &KubeValidatorConfigRule{Level: levelNotice, EnforceAfter: "2026-10-01"}
+	if policies.valid() {
+		t.Error("expected a level and an enforceAfter date together to be invalid")
+	}
+}
@@ -0,0 +1,299 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	duplicateContainerNameTitle = "Duplicate container name"
+	duplicateMountPathTitle     = "Duplicate volume mount path"
+	duplicateEnvNameTitle       = "Duplicate environment variable"
+	undefinedEnvReferenceTitle  = "Undefined environment variable"
+	requestExceedsLimitTitle    = "Resource request exceeds limit"
+)
+
+// envReference matches $(VAR) references in the command and args of
+// containers. A reference preceded by an odd number of $ is escaped.
+var envReference = regexp.MustCompile(`(\$+)\(([-._a-zA-Z0-9]+)\)`)
+
+// container is an entry in the containers or initContainers of a pod spec
+type container struct {
+	path   []interface{}
+	object map[string]interface{}
+}
+
+func (c *container) name() string {
+	s, _ := c.object["name"].(string)
+	return s
+}
+
+func (c *container) get(path ...interface{}) interface{} {
+	return valueAt(c.object, path)
+}
+
+// podSpecPath returns the path to the pod spec of workload resources
+func (r *Resource) podSpecPath() ([]interface{}, bool) {
+	template, ok := r.podTemplatePath()
+	if !ok {
+		return nil, false
+	}
+	return joinPath(template, "spec"), true
+}
+
+// containers returns the init containers followed by the containers of
+// workload resources
+func (r *Resource) containers() []*container {
+	spec, ok := r.podSpecPath()
+	if !ok {
+		return nil
+	}
+	var containers []*container
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _ := r.get(joinPath(spec, field)...).([]interface{})
+		for i, item := range list {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containers = append(containers, &container{
+				path:   joinPath(spec, field, i),
+				object: object,
+			})
+		}
+	}
+	return containers
+}
+
+// checkDuplicateContainerNames fails when the name of a container has already
+// been used by another container or init container of the same pod, which the
+// API server rejects.
+func checkDuplicateContainerNames(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().DuplicateContainerNames.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	seen := map[string]bool{}
+	for _, c := range r.containers() {
+		name := c.name()
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			annotations = append(annotations, r.annotation(level, duplicateContainerNameTitle,
+				fmt.Sprintf("%s has more than one container named %s.", r, name),
+				joinPath(c.path, "name")...))
+		}
+		seen[name] = true
+	}
+	return annotations
+}
+
+// checkDuplicateMountPaths fails when a container mounts more than one volume
+// at the same path, shadowing all but one of them.
+func checkDuplicateMountPaths(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().DuplicateMountPaths.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		mounts, _ := c.get("volumeMounts").([]interface{})
+		seen := map[string]bool{}
+		for i := range mounts {
+			mountPath, _ := c.get("volumeMounts", i, "mountPath").(string)
+			mountPath = strings.TrimSuffix(mountPath, "/")
+			if mountPath == "" {
+				continue
+			}
+			if seen[mountPath] {
+				annotations = append(annotations, r.annotation(level, duplicateMountPathTitle,
+					fmt.Sprintf("Container %s of %s mounts more than one volume at %s.", c.name(), r, mountPath),
+					joinPath(c.path, "volumeMounts", i, "mountPath")...))
+			}
+			seen[mountPath] = true
+		}
+	}
+	return annotations
+}
+
+// checkDuplicateEnvNames warns when a container defines an environment
+// variable more than once, or overrides a key injected by envFrom from a
+// ConfigMap or Secret in the Pull Request. Only the last definition is used.
+func checkDuplicateEnvNames(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().DuplicateEnvNames.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		injected := map[string]string{}
+		sources, _ := c.get("envFrom").([]interface{})
+		for i := range sources {
+			source, keys := r.envFromKeys(c, i, resources)
+			var duplicates []string
+			for _, key := range keys {
+				if _, ok := injected[key]; ok {
+					duplicates = append(duplicates, key)
+				}
+				injected[key] = source
+			}
+			if len(duplicates) > 0 {
+				annotations = append(annotations, r.annotation(level, duplicateEnvNameTitle,
+					fmt.Sprintf("%s in container %s of %s overrides %s injected by an earlier envFrom source.", source, c.name(), r, strings.Join(duplicates, ", ")),
+					joinPath(c.path, "envFrom", i)...))
+			}
+		}
+
+		env, _ := c.get("env").([]interface{})
+		seen := map[string]bool{}
+		for i := range env {
+			name, _ := c.get("env", i, "name").(string)
+			if name == "" {
+				continue
+			}
+			if seen[name] {
+				annotations = append(annotations, r.annotation(level, duplicateEnvNameTitle,
+					fmt.Sprintf("Container %s of %s defines %s more than once. Only the last value is used.", c.name(), r, name),
+					joinPath(c.path, "env", i, "name")...))
+			} else if source, ok := injected[name]; ok {
+				annotations = append(annotations, r.annotation(level, duplicateEnvNameTitle,
+					fmt.Sprintf("Container %s of %s defines %s, overriding the key injected from %s.", c.name(), r, name, source),
+					joinPath(c.path, "env", i, "name")...))
+			}
+			seen[name] = true
+		}
+	}
+	return annotations
+}
+
+// envFromKeys returns a description of the ConfigMap or Secret referenced by
+// the envFrom entry of c at index, along with the environment variables it
+// injects. No keys are returned when the source isn't in the Pull Request.
+func (r *Resource) envFromKeys(c *container, index int, resources []*Resource) (string, []string) {
+	prefix, _ := c.get("envFrom", index, "prefix").(string)
+	for kind, field := range map[string]string{"ConfigMap": "configMapRef", "Secret": "secretRef"} {
+		name, _ := c.get("envFrom", index, field, "name").(string)
+		if name == "" {
+			continue
+		}
+		source := fmt.Sprintf("%s %s", kind, name)
+		for _, candidate := range resources {
+			if candidate.Kind() != kind || candidate.Name() != name || candidate.Namespace() != r.Namespace() {
+				continue
+			}
+			var keys []string
+			for _, data := range []string{"data", "stringData", "binaryData"} {
+				for key := range stringMap(candidate.get(data)) {
+					keys = append(keys, prefix+key)
+				}
+			}
+			sort.Strings(keys)
+			return source, keys
+		}
+		return source, nil
+	}
+	return "", nil
+}
+
+// definedEnv returns the environment variables defined by c, and false when
+// they can't be determined because an envFrom source isn't in the Pull
+// Request
+func (r *Resource) definedEnv(c *container, resources []*Resource) (map[string]bool, bool) {
+	defined := map[string]bool{}
+	sources, _ := c.get("envFrom").([]interface{})
+	for i := range sources {
+		_, keys := r.envFromKeys(c, i, resources)
+		if keys == nil {
+			return nil, false
+		}
+		for _, key := range keys {
+			defined[key] = true
+		}
+	}
+	env, _ := c.get("env").([]interface{})
+	for i := range env {
+		if name, _ := c.get("env", i, "name").(string); name != "" {
+			defined[name] = true
+		}
+	}
+	return defined, true
+}
+
+// checkUndefinedEnvReferences warns when the command or args of a container
+// reference an environment variable with $(VAR) that the container doesn't
+// define, as the reference is passed to the container as is.
+func checkUndefinedEnvReferences(r *Resource, resources []*Resource) Annotations {
+	rule := r.policies().UndefinedEnvReferences
+	if rule == nil {
+		return nil
+	}
+	level := rule.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		defined, ok := r.definedEnv(c, resources)
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"command", "args"} {
+			values, _ := c.get(field).([]interface{})
+			for i, value := range values {
+				s, _ := value.(string)
+				for _, match := range envReference.FindAllStringSubmatch(s, -1) {
+					if len(match[1])%2 == 0 || defined[match[2]] {
+						continue
+					}
+					annotations = append(annotations, r.annotation(level, undefinedEnvReferenceTitle,
+						fmt.Sprintf("Container %s of %s references $(%s) in its %s, but doesn't define it, so it won't be expanded.", c.name(), r, match[2], field),
+						joinPath(c.path, field, i)...))
+				}
+			}
+		}
+	}
+	return annotations
+}
+
+// checkRequestsWithinLimits fails when a container requests more of a resource
+// than its limit allows, which the API server rejects.
+func checkRequestsWithinLimits(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().RequestsWithinLimits.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		requests := stringMap(c.get("resources", "requests"))
+		limits := stringMap(c.get("resources", "limits"))
+		for _, name := range sortedKeys(requests) {
+			limit, ok := limits[name]
+			if !ok {
+				continue
+			}
+			requested, err := parseQuantity(requests[name])
+			if err != nil {
+				continue
+			}
+			limited, err := parseQuantity(limit)
+			if err != nil {
+				continue
+			}
+			if requested.Cmp(limited) > 0 {
+				annotations = append(annotations, r.annotation(level, requestExceedsLimitTitle,
+					fmt.Sprintf("Container %s of %s requests %s of %s, more than its limit of %s.", c.name(), r, requests[name], name, limit),
+					joinPath(c.path, "resources", "requests", name)...))
+			}
+		}
+	}
+	return annotations
+}
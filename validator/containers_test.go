@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestDuplicateContainerNames(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-names.yaml")
+
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/containers/duplicate-names.yaml"),
+			StartLine:       github.Int(20),
+			AnnotationLevel: github.String("failure"),
+		},
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/containers/duplicate-names.yaml"),
+			StartLine:       github.Int(22),
+			AnnotationLevel: github.String("failure"),
+		},
+	)
+}
+
+func TestDuplicateContainerNamesCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		DuplicateContainerNames: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/containers/duplicate-names.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestDuplicateMountPaths(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-mount-paths.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/containers/duplicate-mount-paths.yaml"),
+		StartLine:       github.Int(15),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestDuplicateEnvNames(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-env.yaml")
+
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/containers/duplicate-env.yaml"),
+			StartLine:       github.Int(31),
+			AnnotationLevel: github.String("warning"),
+		},
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/containers/duplicate-env.yaml"),
+			StartLine:       github.Int(35),
+			AnnotationLevel: github.String("warning"),
+		},
+	)
+}
+
+func TestUndefinedEnvReferences(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		UndefinedEnvReferences: &KubeValidatorConfigRule{},
+	}, "fixtures/checks/containers/env-references.yaml")
+
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/containers/env-references.yaml"),
+		StartLine:       github.Int(27),
+		AnnotationLevel: github.String("warning"),
+	})
+	if len(annotations) == 1 && !strings.Contains(annotations[0].GetMessage(), "$(DB_HOST)") {
+		t.Errorf("expected the undefined variable in the message, got %s", annotations[0].GetMessage())
+	}
+}
+
+func TestRequestsWithinLimits(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/requests.yaml")
+
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/containers/requests.yaml"),
+		StartLine:       github.Int(20),
+		AnnotationLevel: github.String("failure"),
+	})
+	if want := "requests 2Gi of memory, more than its limit of 1Gi"; len(annotations) == 1 && !strings.Contains(annotations[0].GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotations[0].GetMessage())
+	}
+}
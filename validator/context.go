@@ -4,12 +4,26 @@ import (
 	"context"
 	"log"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 )
 
+// Names of the event handlers which can be enabled
+const (
+	checkSuiteHandler   = "checkSuite"
+	pullRequestHandler  = "pullRequest"
+	checkRunHandler     = "checkRun"
+	installationHandler = "installation"
+
+	// kubevalidator doesn't act on push events, so enabling or disabling the
+	// push handler has no effect
+	pushHandler = "push"
+)
+
 // Context contains an event payload an a configured client
 type Context struct {
 	Event     interface{}
@@ -17,19 +31,123 @@ type Context struct {
 	Ctx       *context.Context
 	AppID     *int
 	AppGitHub *github.Client
+
+	// EnabledHandlers lists the event handlers that should be run. All
+	// handlers are enabled when empty.
+	EnabledHandlers []string
+
+	// LatestCheckSuiteOnly ignores check suites for commits which are no
+	// longer the head of their Pull Request
+	LatestCheckSuiteOnly bool
+
+	// DeliveryID identifies the webhook delivery being processed in logs
+	DeliveryID string
+
+	// GistGithub uploads reports too long for a check run summary to Gists.
+	// Installation tokens can't create Gists, so it must authenticate as a
+	// user. Long summaries are truncated instead when it's nil.
+	GistGithub *github.Client
+
+	// ImagePolicySources are globs matching the image policy sources that
+	// configurations may use. No sources are allowed when empty.
+	ImagePolicySources []string
+
+	// SchemaLocations are globs matching the schema locations that
+	// configurations may use. No locations are allowed when empty.
+	SchemaLocations []string
+
+	// DryRun records the check runs that would be created rather than
+	// creating them, and skips every other change to GitHub
+	DryRun bool
+
+	// previousFilenames maps the files renamed by the Pull Request to their
+	// names in its base
+	previousFilenames map[string]string
+
+	// checkRuns are the check runs recorded during a dry run
+	checkRuns []github.CreateCheckRunOptions
+
+	// panicked is set when processing the event panicked
+	panicked bool
+}
+
+// validHandlers returns an error naming the first of names which isn't an
+// event handler
+func validHandlers(names []string) error {
+	for _, name := range names {
+		switch name {
+		case checkSuiteHandler, pullRequestHandler, checkRunHandler, pushHandler, installationHandler:
+		default:
+			return errors.Errorf("Unknown handler %q, expected one of %s, %s, %s, %s or %s", name, checkSuiteHandler, pullRequestHandler, checkRunHandler, pushHandler, installationHandler)
+		}
+	}
+	return nil
 }
 
-// Process handles webhook events kinda like Probot does
-func (c *Context) Process() bool {
+// handlerEnabled returns true when the named event handler should be run,
+// logging when it shouldn't
+func (c *Context) handlerEnabled(name string) bool {
+	if len(c.EnabledHandlers) == 0 || containsString(c.EnabledHandlers, name) {
+		return true
+	}
+	log.Printf("ignoring %s event, the %s handler is disabled\n", reflect.TypeOf(c.Event).String(), name)
+	return false
+}
+
+// Process handles webhook events kinda like Probot does. A panic while
+// processing an event is recovered so that it doesn't affect other deliveries.
+func (c *Context) Process() (processed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.recoverPanic(r)
+			processed = false
+		}
+	}()
+	return c.process()
+}
+
+// recoverPanic logs a panic recovered while processing an event, and
+// concludes the check run of a check suite with an error so that it doesn't
+// remain in progress forever
+func (c *Context) recoverPanic(r interface{}) {
+	c.panicked = true
+	log.Printf("panic processing delivery %s (%s): %v\n%s", c.DeliveryID, reflect.TypeOf(c.Event).String(), r, debug.Stack())
+
+	e, ok := c.Event.(*github.CheckSuiteEvent)
+	if !ok {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic concluding the check run for delivery %s: %v\n", c.DeliveryID, r)
+		}
+	}()
+	startedAt := time.Now()
+	c.createErrorCheckRun(&startedAt, e)
+}
+
+func (c *Context) process() bool {
 	switch e := c.Event.(type) {
 	case *github.CheckSuiteEvent:
+		if !c.handlerEnabled(checkSuiteHandler) {
+			return false
+		}
 		c.ProcessCheckSuite(c.Event.(*github.CheckSuiteEvent))
 		return true
 	case *github.PullRequestEvent:
+		if !c.handlerEnabled(pullRequestHandler) {
+			return false
+		}
 		return c.ProcessPrEvent(c.Event.(*github.PullRequestEvent))
 	case *github.CheckRunEvent:
+		if !c.handlerEnabled(checkRunHandler) {
+			return false
+		}
 		return c.ProcessCheckRunEvent(c.Event.(*github.CheckRunEvent))
 	case *github.InstallationEvent:
+		if !c.handlerEnabled(installationHandler) {
+			return false
+		}
 		err := c.LogInstallationCount()
 		if err != nil {
 			log.Printf("%+v\n", err)
@@ -37,6 +155,9 @@ func (c *Context) Process() bool {
 		}
 		return true
 	case *github.InstallationRepositoriesEvent:
+		if !c.handlerEnabled(installationHandler) {
+			return false
+		}
 		err := c.LogInstallationCount()
 		if err != nil {
 			log.Printf("%+v\n", err)
@@ -53,17 +174,26 @@ func (c *Context) Process() bool {
 // associated with PRs.
 func (c *Context) ProcessCheckSuite(e *github.CheckSuiteEvent) {
 	if *e.Action == "created" || *e.Action == "requested" || *e.Action == "rerequested" {
+		if c.LatestCheckSuiteOnly && superseded(e) {
+			log.Printf("ignoring check suite for %s, it's no longer the head of its Pull Request\n", e.CheckSuite.GetHeadSHA())
+			return
+		}
+
+		timings := &timings{}
+		stageStart := time.Now()
 		createCheckRunErr := c.createInitialCheckRun(e)
 		if createCheckRunErr != nil {
 			// TODO return a 500 to signal that retry is preferred
 			log.Println(errors.Wrap(createCheckRunErr, "Couldn't create check run"))
 			return
 		}
+		timings.track(stageCheckRun, stageStart)
 
 		checkRunStart := time.Now()
 		var annotations []*github.CheckRunAnnotation
 		var candidates Candidates
 
+		stageStart = time.Now()
 		config, configAnnotation, err := c.kubeValidatorConfigOrAnnotation(e)
 		if err != nil {
 			c.createConfigMissingCheckRun(&checkRunStart, e)
@@ -74,6 +204,13 @@ func (c *Context) ProcessCheckSuite(e *github.CheckSuiteEvent) {
 			c.createConfigInvalidCheckRun(&checkRunStart, e, annotations)
 			return
 		}
+		config = config.forBranch(baseBranch(e))
+		if ignoreBytes, err := c.bytesForFilename(e, ignorePath); err == nil {
+			config.ignore = parseIgnoreFile(*ignoreBytes)
+		}
+		if baselineBytes, err := c.bytesForFilename(e, baselinePath); err == nil {
+			config.baseline = parseBaselineFile(*baselineBytes)
+		}
 
 		// Determine which files to validate
 		changedFileList, fileListError := c.changedFileList(e)
@@ -84,11 +221,54 @@ func (c *Context) ProcessCheckSuite(e *github.CheckSuiteEvent) {
 		}
 
 		candidates = config.matchingCandidates(c, changedFileList)
+		if limited := candidates.limit(config.maxCandidates()); limited != nil {
+			annotations = append(annotations, limited)
+		}
 		annotations = append(annotations, candidates.LoadBytes()...)
-		annotations = append(annotations, candidates.Validate()...)
+		if discovery := config.crdDiscovery(); discovery != nil {
+			crds, notice, err := c.discoverCRDs(e, discovery)
+			if err != nil {
+				log.Println(err)
+			}
+			if notice != nil {
+				annotations = append(annotations, notice)
+			}
+			candidates.setCRDs(crds)
+		}
+		candidates.loadImagePolicies(c.imagePolicySourceAllowed)
+		candidates.allowSchemaLocations(c.schemaLocationAllowed)
+		timings.track(stageFiles, stageStart)
+		if config.debug() {
+			config.timings = timings
+		}
+
+		policies := config.policiesScheduled()
+		config.schemasOnly = !policies
+		stageStart = time.Now()
+		if config.failFast() {
+			validated, stopped := candidates.ValidateFailFast(policies, config.baseline)
+			config.stoppedEarly = stopped
+			annotations = append(annotations, validated...)
+			timings.track(stageValidation, stageStart)
+		} else {
+			validated := candidates.ValidateSchemas()
+			timings.track(stageSchemas, stageStart)
+			if policies {
+				stageStart = time.Now()
+				validated = append(validated, candidates.Check()...)
+				sort.Sort(validated)
+				timings.track(stagePolicies, stageStart)
+			}
+			annotations = append(annotations, validated...)
+		}
+		annotations = candidates.suppressBaselined(annotations, config.baseline)
+		if config.snippets() {
+			candidates.addSnippets(annotations)
+		}
+		Annotations(annotations).truncateMessages(config.maxMessageLength())
 
 		// Annotate the PR
-		finalCheckRunErr := c.createFinalCheckRun(&checkRunStart, e, candidates, annotations)
+		finalCheckRunErr := c.createFinalCheckRun(&checkRunStart, e, candidates, annotations, config)
 		if finalCheckRunErr != nil {
 			// TODO return a 500 to signal that retry is preferred
 			log.Println(errors.Wrap(finalCheckRunErr, "Couldn't create check run"))
@@ -98,6 +278,59 @@ func (c *Context) ProcessCheckSuite(e *github.CheckSuiteEvent) {
 	return
 }
 
+// baseBranch returns the base branch of the first Pull Request associated with
+// a check suite
+func baseBranch(e *github.CheckSuiteEvent) string {
+	if e.CheckSuite == nil {
+		return ""
+	}
+	for _, pr := range e.CheckSuite.PullRequests {
+		if ref := pr.GetBase().GetRef(); ref != "" {
+			return ref
+		}
+	}
+	return ""
+}
+
+// superseded returns true when every Pull Request associated with a check
+// suite has a head other than the commit being checked
+func superseded(e *github.CheckSuiteEvent) bool {
+	if e.CheckSuite == nil || len(e.CheckSuite.PullRequests) == 0 {
+		return false
+	}
+	for _, pr := range e.CheckSuite.PullRequests {
+		if sha := pr.GetHead().GetSHA(); sha == "" || sha == e.CheckSuite.GetHeadSHA() {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSuitesForSHA returns the check suites for the commit sha
+func checkSuitesForSHA(suites []*github.CheckSuite, sha string) []*github.CheckSuite {
+	var matching []*github.CheckSuite
+	for _, suite := range suites {
+		if suite.GetHeadSHA() == sha {
+			matching = append(matching, suite)
+		}
+	}
+	return matching
+}
+
+// baseSHA returns the SHA of the base of the first Pull Request associated
+// with a check suite
+func baseSHA(e *github.CheckSuiteEvent) string {
+	if e.CheckSuite == nil {
+		return ""
+	}
+	for _, pr := range e.CheckSuite.PullRequests {
+		if sha := pr.GetBase().GetSHA(); sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
+
 // ProcessPrEvent re-requests check suites on PRs when they're opened or re-opened
 func (c *Context) ProcessPrEvent(e *github.PullRequestEvent) bool {
 	if *e.Action == "opened" || *e.Action == "reopened" {
@@ -108,9 +341,17 @@ func (c *Context) ProcessPrEvent(e *github.PullRequestEvent) bool {
 		if err != nil {
 			log.Printf("%+v\n", err)
 		}
+		if c.LatestCheckSuiteOnly && results != nil {
+			suites := checkSuitesForSHA(results.CheckSuites, e.PullRequest.Head.GetSHA())
+			if len(suites) != 1 {
+				return false
+			}
+			results.CheckSuites = suites
+			results.Total = github.Int(1)
+		}
 		if results.GetTotal() == 1 {
 			suite := results.CheckSuites[0]
-			_, err := c.Github.Checks.ReRequestCheckSuite(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), suite.GetID())
+			err := c.reRequestCheckSuite(e.Repo, suite.GetID())
 			if err != nil {
 				log.Printf("%+v\n", err)
 			}
@@ -124,7 +365,7 @@ func (c *Context) ProcessPrEvent(e *github.PullRequestEvent) bool {
 func (c *Context) ProcessCheckRunEvent(e *github.CheckRunEvent) bool {
 	if *e.Action == "rerequested" {
 
-		_, err := c.Github.Checks.ReRequestCheckSuite(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), e.CheckRun.CheckSuite.GetID())
+		err := c.reRequestCheckSuite(e.Repo, e.CheckRun.CheckSuite.GetID())
 		if err != nil {
 			log.Printf("%+v\n", err)
 			return false
@@ -134,6 +375,17 @@ func (c *Context) ProcessCheckRunEvent(e *github.CheckRunEvent) bool {
 	return false
 }
 
+// reRequestCheckSuite re-requests the check suite with id, unless this is a
+// dry run
+func (c *Context) reRequestCheckSuite(repo *github.Repository, id int64) error {
+	if c.DryRun {
+		log.Printf("dry run, not re-requesting check suite %d\n", id)
+		return nil
+	}
+	_, err := c.Github.Checks.ReRequestCheckSuite(*c.Ctx, repo.GetOwner().GetLogin(), repo.GetName(), id)
+	return err
+}
+
 // LogInstallationCount logs the number of installations to help keep track of
 // eligibility for inclusion in the GitHub Marketplace.
 // https://developer.github.com/apps/marketplace/creating-and-submitting-your-app-for-approval/requirements-for-listing-an-app-on-github-marketplace/
@@ -2,8 +2,10 @@ package validator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/github"
@@ -109,6 +111,18 @@ func TestPullRequestTestingTooManyCheckSuites(t *testing.T) {
 	return
 }
 
+func TestUnknownHandlersFailOnStartup(t *testing.T) {
+	s := &Server{EnabledHandlers: []string{"checkSuite", "pullrequest"}}
+	err := s.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `"pullrequest"`) {
+		t.Errorf("expected an error naming the unknown handler, got %v", err)
+	}
+
+	if err := validHandlers([]string{"checkSuite", "push"}); err != nil {
+		t.Errorf("expected the push handler to be accepted, got %v", err)
+	}
+}
+
 func TestReRequestedCheckRunReRequestsTheCheckSuite(t *testing.T) {
 	checkRunEvent := &github.CheckRunEvent{
 		Action: github.String("rerequested"),
@@ -145,3 +159,175 @@ func TestReRequestedCheckRunReRequestsTheCheckSuite(t *testing.T) {
 	}
 	return
 }
+
+func TestDisabledPullRequestHandler(t *testing.T) {
+	prEvent := &github.PullRequestEvent{
+		Action: github.String("opened"),
+		PullRequest: &github.PullRequest{
+			Head: &github.PullRequestBranch{
+				Ref: github.String("b"),
+			},
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{
+				Login: github.String("o"),
+			},
+			Name: github.String("r"),
+		},
+	}
+	client, mux, _, teardown := setup()
+	ctx := context.Background()
+	context := &Context{
+		Ctx:             &ctx,
+		Event:           prEvent,
+		Github:          client,
+		AppID:           github.Int(1),
+		EnabledHandlers: []string{"checkSuite", "checkRun"},
+	}
+	defer teardown()
+	mux.HandleFunc("/repos/o/r/commits/b/check-suites", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("ProcessPrEvent was invoked for a disabled handler")
+	})
+	processed := context.Process()
+	if processed {
+		t.Error("PR event expected to be skipped")
+	}
+	return
+}
+
+func TestLatestCheckSuiteOnlyReRequestsCurrentHead(t *testing.T) {
+	prEvent := &github.PullRequestEvent{
+		Action: github.String("reopened"),
+		PullRequest: &github.PullRequest{
+			Head: &github.PullRequestBranch{
+				Ref: github.String("b"),
+				SHA: github.String("new"),
+			},
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{
+				Login: github.String("o"),
+			},
+			Name: github.String("r"),
+		},
+	}
+	client, mux, _, teardown := setup()
+	ctx := context.Background()
+	context := &Context{
+		Ctx:                  &ctx,
+		Event:                prEvent,
+		Github:               client,
+		AppID:                github.Int(1),
+		LatestCheckSuiteOnly: true,
+	}
+	defer teardown()
+	mux.HandleFunc("/repos/o/r/commits/b/check-suites", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{
+			"total_count": 2,
+			"check_suites": [
+				{"id": 4, "head_sha": "old"},
+				{"id": 5, "head_sha": "new"}
+			]
+		}`)
+	})
+	mux.HandleFunc("/repos/o/r/check-suites/4/rerequest", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the check suite of a superseded head was re-requested")
+	})
+	rerequested := false
+	mux.HandleFunc("/repos/o/r/check-suites/5/rerequest", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		rerequested = true
+	})
+	if !context.Process() {
+		t.Error("PR event was never processed")
+	}
+	if !rerequested {
+		t.Error("the check suite of the current head wasn't re-requested")
+	}
+}
+
+func TestLatestCheckSuiteOnlyIgnoresSupersededCheckSuite(t *testing.T) {
+	event := &github.CheckSuiteEvent{
+		Action: github.String("rerequested"),
+		CheckSuite: &github.CheckSuite{
+			HeadSHA: github.String("old"),
+			PullRequests: []*github.PullRequest{{
+				Head: &github.PullRequestBranch{SHA: github.String("new")},
+			}},
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{
+				Login: github.String("o"),
+			},
+			Name: github.String("r"),
+		},
+	}
+	client, mux, _, teardown := setup()
+	ctx := context.Background()
+	context := &Context{
+		Ctx:                  &ctx,
+		Event:                event,
+		Github:               client,
+		LatestCheckSuiteOnly: true,
+	}
+	defer teardown()
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a check run was created for a superseded head")
+	})
+	context.Process()
+
+	if superseded(&github.CheckSuiteEvent{CheckSuite: &github.CheckSuite{
+		HeadSHA: github.String("new"),
+		PullRequests: []*github.PullRequest{{
+			Head: &github.PullRequestBranch{SHA: github.String("new")},
+		}},
+	}}) {
+		t.Error("the check suite of the current head was considered superseded")
+	}
+}
+
+func TestPanicConcludesCheckRunWithError(t *testing.T) {
+	// A nil action panics while processing the check suite
+	event := &github.CheckSuiteEvent{
+		CheckSuite: &github.CheckSuite{
+			HeadSHA: github.String("s"),
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{
+				Login: github.String("o"),
+			},
+			Name: github.String("r"),
+		},
+	}
+	client, mux, _, teardown := setup()
+	ctx := context.Background()
+	context := &Context{
+		Ctx:        &ctx,
+		Event:      event,
+		Github:     client,
+		DeliveryID: "d",
+	}
+	defer teardown()
+	concluded := false
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		v := &github.CreateCheckRunOptions{}
+		json.NewDecoder(r.Body).Decode(v)
+		if v.GetConclusion() != "failure" || v.Output.GetTitle() != "Internal error" {
+			t.Errorf("expected the check run to conclude with an error, got %s", github.Stringify(v))
+		}
+		concluded = true
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+
+	if context.Process() {
+		t.Error("expected an event which panicked not to be processed")
+	}
+	if !context.panicked {
+		t.Error("expected the panic to be recorded")
+	}
+	if !concluded {
+		t.Error("expected the check run to be concluded")
+	}
+}
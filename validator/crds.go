@@ -0,0 +1,274 @@
+package validator
+
+import (
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/garethr/kubeval/kubeval"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// defaultMaxCRDFiles bounds the number of files searched for
+	// CustomResourceDefinitions when CRD discovery doesn't set maxFiles
+	defaultMaxCRDFiles = 100
+
+	crdDiscoveryIncompleteTitle = "CustomResourceDefinition discovery incomplete"
+)
+
+// crdIndex maps the group and kind of custom resources to their
+// CustomResourceDefinition
+type crdIndex map[string]map[string]interface{}
+
+// blobCRDCache holds the CustomResourceDefinitions found in recently searched
+// blobs so that unchanged files are only fetched and parsed once
+var blobCRDCache = newCRDBlobCache(1000)
+
+// crdBlobCache maps blob SHAs to the CustomResourceDefinitions found in them,
+// evicting the least recently used blob beyond size blobs
+type crdBlobCache struct {
+	sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// crdBlobCacheEntry is the CustomResourceDefinitions found in a blob
+type crdBlobCacheEntry struct {
+	sha  string
+	crds []map[string]interface{}
+}
+
+func newCRDBlobCache(size int) *crdBlobCache {
+	return &crdBlobCache{size: size, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// get returns the CustomResourceDefinitions found in the blob with sha, if
+// it's cached
+func (cache *crdBlobCache) get(sha string) ([]map[string]interface{}, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	element, ok := cache.entries[sha]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*crdBlobCacheEntry).crds, true
+}
+
+// add caches the CustomResourceDefinitions found in the blob with sha
+func (cache *crdBlobCache) add(sha string, crds []map[string]interface{}) {
+	cache.Lock()
+	defer cache.Unlock()
+	if element, ok := cache.entries[sha]; ok {
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[sha] = cache.order.PushFront(&crdBlobCacheEntry{sha: sha, crds: crds})
+	if cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*crdBlobCacheEntry).sha)
+	}
+}
+
+// crdKey returns the key of a custom resource's kind in a crdIndex
+func crdKey(group string, kind string) string {
+	return fmt.Sprintf("%s/%s", group, kind)
+}
+
+// parseCRDs returns the CustomResourceDefinitions found in b
+func parseCRDs(b []byte) []map[string]interface{} {
+	var crds []map[string]interface{}
+	for _, r := range parseResources(nil, b) {
+		if r.Kind() == "CustomResourceDefinition" {
+			crds = append(crds, r.object)
+		}
+	}
+	return crds
+}
+
+// add indexes crds by the group and kind of the resources they define
+func (index crdIndex) add(crds []map[string]interface{}) {
+	for _, crd := range crds {
+		group, _ := valueAt(crd, []interface{}{"spec", "group"}).(string)
+		kind, _ := valueAt(crd, []interface{}{"spec", "names", "kind"}).(string)
+		if group != "" && kind != "" {
+			index[crdKey(group, kind)] = crd
+		}
+	}
+}
+
+// lookup returns the CustomResourceDefinition of r and the version of it that
+// r uses, if one was discovered
+func (index crdIndex) lookup(r *Resource) (map[string]interface{}, string) {
+	if len(index) == 0 || r == nil {
+		return nil, ""
+	}
+	parts := strings.SplitN(r.APIVersion(), "/", 2)
+	if len(parts) != 2 {
+		return nil, ""
+	}
+	return index[crdKey(parts[0], r.Kind())], parts[1]
+}
+
+// validateCustomResource validates r against the schema of version in its
+// CustomResourceDefinition
+func validateCustomResource(r *Resource, crd map[string]interface{}, version string) ([]kubeval.ValidationResult, error) {
+	result := kubeval.ValidationResult{
+		FileName: r.candidate.file.GetFilename(),
+		Kind:     r.Kind(),
+	}
+	openAPISchema := crdSchema(crd, version)
+	if openAPISchema == nil {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("%s %s isn't served by the CustomResourceDefinition %s", r.APIVersion(), r.Kind(), valueAt(crd, []interface{}{"metadata", "name"}))
+	}
+
+	results, err := gojsonschema.Validate(gojsonschema.NewGoLoader(openAPISchema), gojsonschema.NewGoLoader(r.object))
+	if err != nil {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("Problem loading the schema of %s %s: %s", r.APIVersion(), r.Kind(), err)
+	}
+	if !results.Valid() {
+		result.Errors = results.Errors()
+	}
+	return []kubeval.ValidationResult{result}, nil
+}
+
+// setCRDs validates custom resources in the candidates against the
+// CustomResourceDefinitions in index
+func (c *Candidates) setCRDs(index crdIndex) {
+	for _, candidate := range *c {
+		candidate.crds = index
+	}
+}
+
+// matches returns true when path matches one of the discovery's globs
+func (discovery *KubeValidatorConfigCRDDiscovery) matches(path string) bool {
+	for _, glob := range discovery.Globs {
+		if matched, _ := doublestar.Match(glob, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// maxFiles returns the number of files searched for CustomResourceDefinitions
+func (discovery *KubeValidatorConfigCRDDiscovery) maxFiles() int {
+	if discovery.MaxFiles > 0 {
+		return discovery.MaxFiles
+	}
+	return defaultMaxCRDFiles
+}
+
+// crdDiscoveryNotice returns a notice on the configuration at path that some
+// files matching discovery weren't searched for CustomResourceDefinitions, for
+// reason. It's logged too, as it usually calls for a configuration change.
+func crdDiscoveryNotice(path string, reason string) *github.CheckRunAnnotation {
+	message := fmt.Sprintf("Some files matching discoverCRDs weren't searched for CustomResourceDefinitions as %s. Custom resources defined in them may fail validation.", reason)
+	log.Println(message)
+	return &github.CheckRunAnnotation{
+		Path:            github.String(path),
+		StartLine:       github.Int(1),
+		EndLine:         github.Int(1),
+		AnnotationLevel: github.String(levelNotice),
+		Title:           github.String(crdDiscoveryIncompleteTitle),
+		Message:         github.String(message),
+	}
+}
+
+// maxFilesReason explains that discovery stopped at maxFiles
+func (discovery *KubeValidatorConfigCRDDiscovery) maxFilesReason() string {
+	return fmt.Sprintf("more than maxFiles (%d) files matched", discovery.maxFiles())
+}
+
+// discoverCRDs indexes the CustomResourceDefinitions in the files matching
+// discovery anywhere in the tree of the commit being checked, whether or not
+// they changed. Blobs are cached by SHA, so unchanged files are only fetched
+// once. A notice is returned when some matching files weren't searched.
+func (c *Context) discoverCRDs(e *github.CheckSuiteEvent, discovery *KubeValidatorConfigCRDDiscovery) (crdIndex, *github.CheckRunAnnotation, error) {
+	owner, repo := e.Repo.GetOwner().GetLogin(), e.Repo.GetName()
+	tree, _, err := c.Github.Git.GetTree(*c.Ctx, owner, repo, e.CheckSuite.GetHeadSHA(), true)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Couldn't list files to discover CustomResourceDefinitions in")
+	}
+
+	var notice *github.CheckRunAnnotation
+	if tree.GetTruncated() {
+		notice = crdDiscoveryNotice(configPath, "the repository has more files than GitHub lists at once")
+	}
+	index := crdIndex{}
+	searched := 0
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" || !discovery.matches(entry.GetPath()) {
+			continue
+		}
+		if searched == discovery.maxFiles() {
+			notice = crdDiscoveryNotice(configPath, discovery.maxFilesReason())
+			break
+		}
+		searched++
+
+		crds, ok := blobCRDCache.get(entry.GetSHA())
+		if !ok {
+			b, _, err := c.Github.Git.GetBlobRaw(*c.Ctx, owner, repo, entry.GetSHA())
+			if err != nil {
+				return nil, nil, errors.Wrap(err, fmt.Sprintf("Couldn't load %s", entry.GetPath()))
+			}
+			crds = parseCRDs(b)
+			blobCRDCache.add(entry.GetSHA(), crds)
+		}
+		index.add(crds)
+	}
+	return index, notice, nil
+}
+
+// discoverCRDs indexes the CustomResourceDefinitions in the files under Root
+// matching discovery. A notice is returned when some matching files weren't
+// searched.
+func (cli *CLI) discoverCRDs(discovery *KubeValidatorConfigCRDDiscovery) (crdIndex, *github.CheckRunAnnotation, error) {
+	var notice *github.CheckRunAnnotation
+	index := crdIndex{}
+	searched := 0
+	err := filepath.Walk(cli.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relative, err := filepath.Rel(cli.Root, path)
+		if err != nil {
+			return err
+		}
+		if notice != nil || !discovery.matches(filepath.ToSlash(relative)) {
+			return nil
+		}
+		if searched == discovery.maxFiles() {
+			notice = crdDiscoveryNotice(cli.ConfigPath, discovery.maxFilesReason())
+			return nil
+		}
+		searched++
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		index.add(parseCRDs(b))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Couldn't discover CustomResourceDefinitions in %s", cli.Root))
+	}
+	return index, notice, nil
+}
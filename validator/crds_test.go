@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestDiscoveredCRDsValidateUnchangedDefinitions(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	crd, err := ioutil.ReadFile("../fixtures/checks/crds/operator/crd.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux.HandleFunc("/repos/o/r/git/trees/head", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{
+			"sha": "head",
+			"tree": [
+				{"path": "operator", "type": "tree", "sha": "a"},
+				{"path": "operator/crd.yaml", "type": "blob", "sha": "crd-blob"},
+				{"path": "README.md", "type": "blob", "sha": "readme-blob"}
+			]
+		}`)
+	})
+	mux.HandleFunc("/repos/o/r/git/blobs/crd-blob", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write(crd)
+	})
+
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client}
+	e := &github.CheckSuiteEvent{
+		CheckSuite: &github.CheckSuite{HeadSHA: github.String("head")},
+		Repo: &github.Repository{
+			Name:  github.String("r"),
+			Owner: &github.User{Login: github.String("o")},
+		},
+	}
+	crds, notice, err := c.discoverCRDs(e, &KubeValidatorConfigCRDDiscovery{Globs: []string{"**/*.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notice != nil {
+		t.Errorf("expected every file to be searched, got %s", notice.GetMessage())
+	}
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/crds/examples/backup.yaml")
+	candidates.setCRDs(crds)
+	annotations := candidates.Validate()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/crds/examples/backup.yaml"),
+		StartLine:       github.Int(9),
+		AnnotationLevel: github.String(levelFailure),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/crds/examples/backup.yaml"),
+		StartLine:       github.Int(9),
+		AnnotationLevel: github.String(levelFailure),
+	})
+}
+
+func TestCLIDiscoversCRDs(t *testing.T) {
+	cli := &CLI{Root: "../fixtures/checks/crds"}
+	crds, notice, err := cli.discoverCRDs(&KubeValidatorConfigCRDDiscovery{Globs: []string{"operator/*.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notice != nil {
+		t.Errorf("expected every file to be searched, got %s", notice.GetMessage())
+	}
+	if _, ok := crds[crdKey("example.com", "Backup")]; !ok || len(crds) != 1 {
+		t.Errorf("expected the Backup CustomResourceDefinition to be discovered, got %v", crds)
+	}
+
+	crds, _, err = cli.discoverCRDs(&KubeValidatorConfigCRDDiscovery{Globs: []string{"examples/*.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crds) != 0 {
+		t.Errorf("expected no CustomResourceDefinitions outside of the globs, got %v", crds)
+	}
+}
+
+func TestIncompleteCRDDiscoveryIsNoticed(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/repos/o/r/git/trees/head", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "head", "truncated": true, "tree": []}`)
+	})
+
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client}
+	e := &github.CheckSuiteEvent{
+		CheckSuite: &github.CheckSuite{HeadSHA: github.String("head")},
+		Repo: &github.Repository{
+			Name:  github.String("r"),
+			Owner: &github.User{Login: github.String("o")},
+		},
+	}
+	_, notice, err := c.discoverCRDs(e, &KubeValidatorConfigCRDDiscovery{Globs: []string{"**/*.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notice.GetTitle() != crdDiscoveryIncompleteTitle || notice.GetAnnotationLevel() != levelNotice || notice.GetPath() != configPath {
+		t.Errorf("expected a notice that the tree was truncated, got %s", github.Stringify(notice))
+	}
+
+	cli := &CLI{Root: "../fixtures/checks/crds", ConfigPath: "kubevalidator.yaml"}
+	_, notice, err = cli.discoverCRDs(&KubeValidatorConfigCRDDiscovery{Globs: []string{"**/*.yaml"}, MaxFiles: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notice.GetTitle() != crdDiscoveryIncompleteTitle || !strings.Contains(notice.GetMessage(), "maxFiles (1)") {
+		t.Errorf("expected a notice that maxFiles was reached, got %s", github.Stringify(notice))
+	}
+}
+
+func TestCRDBlobCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newCRDBlobCache(2)
+	cache.add("a", nil)
+	cache.add("b", nil)
+	cache.get("a")
+	cache.add("c", nil)
+
+	for sha, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.get(sha); ok != want {
+			t.Errorf("%s: expected cached to be %v, got %v", sha, want, ok)
+		}
+	}
+}
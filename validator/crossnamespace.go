@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const crossNamespaceReferenceTitle = "Cross-namespace reference"
+
+// externalNameNamespace matches the cluster DNS name of a Service, capturing
+// its namespace
+var externalNameNamespace = regexp.MustCompile(`^[^.]+\.([^.]+)\.svc(\.|$)`)
+
+// gatewayRouteKinds are the Gateway API routes whose parents and backends may
+// be in other namespaces
+var gatewayRouteKinds = map[string]bool{
+	"GRPCRoute": true,
+	"HTTPRoute": true,
+	"TCPRoute":  true,
+	"TLSRoute":  true,
+	"UDPRoute":  true,
+}
+
+// namespaceReference is a reference from a resource to another in namespace
+type namespaceReference struct {
+	namespace string
+	target    string
+	path      []interface{}
+
+	// implicit is set for references that don't name a namespace, but only
+	// resolve to a resource in another namespace in the Pull Request
+	implicit bool
+}
+
+// namespaceReferences returns the references of r to resources in a
+// namespace, whether or not it's the namespace of r
+func (r *Resource) namespaceReferences(resources []*Resource) []namespaceReference {
+	var references []namespaceReference
+	switch {
+	case r.Kind() == "Service":
+		if match := externalNameNamespace.FindStringSubmatch(r.getString("spec", "externalName")); match != nil {
+			references = append(references, namespaceReference{
+				namespace: match[1],
+				target:    fmt.Sprintf("the Service %s", r.getString("spec", "externalName")),
+				path:      []interface{}{"spec", "externalName"},
+			})
+		}
+	case r.Kind() == "Ingress":
+		for _, path := range r.ingressBackendServices() {
+			name := r.getString(path...)
+			if service(r.Namespace(), name, resources) != nil {
+				continue
+			}
+			for _, other := range resources {
+				if other.Kind() == "Service" && other.Name() == name {
+					references = append(references, namespaceReference{
+						namespace: other.Namespace(),
+						target:    fmt.Sprintf("the Service %s", name),
+						path:      path,
+						implicit:  true,
+					})
+					break
+				}
+			}
+		}
+	case r.Kind() == "RoleBinding":
+		subjects, _ := r.get("subjects").([]interface{})
+		for i := range subjects {
+			if namespace := r.getString("subjects", i, "namespace"); r.getString("subjects", i, "kind") == "ServiceAccount" && namespace != "" {
+				references = append(references, namespaceReference{
+					namespace: namespace,
+					target:    fmt.Sprintf("the ServiceAccount %s", r.getString("subjects", i, "name")),
+					path:      []interface{}{"subjects", i, "namespace"},
+				})
+			}
+		}
+	case gatewayRouteKinds[r.Kind()]:
+		parents, _ := r.get("spec", "parentRefs").([]interface{})
+		for i := range parents {
+			references = append(references, r.gatewayReference([]interface{}{"spec", "parentRefs", i}, "Gateway")...)
+		}
+		rules, _ := r.get("spec", "rules").([]interface{})
+		for i := range rules {
+			backends, _ := r.get("spec", "rules", i, "backendRefs").([]interface{})
+			for j := range backends {
+				references = append(references, r.gatewayReference([]interface{}{"spec", "rules", i, "backendRefs", j}, "Service")...)
+			}
+		}
+	}
+	return references
+}
+
+// ingressBackendServices returns the paths of the names of the Services that
+// the backends of an Ingress route to
+func (r *Resource) ingressBackendServices() [][]interface{} {
+	var paths [][]interface{}
+	backends := [][]interface{}{{"spec", "defaultBackend"}, {"spec", "backend"}}
+	rules, _ := r.get("spec", "rules").([]interface{})
+	for i := range rules {
+		httpPaths, _ := r.get("spec", "rules", i, "http", "paths").([]interface{})
+		for j := range httpPaths {
+			backends = append(backends, []interface{}{"spec", "rules", i, "http", "paths", j, "backend"})
+		}
+	}
+	for _, backend := range backends {
+		if r.getString(joinPath(backend, "service", "name")...) != "" {
+			paths = append(paths, joinPath(backend, "service", "name"))
+		} else if r.getString(joinPath(backend, "serviceName")...) != "" {
+			paths = append(paths, joinPath(backend, "serviceName"))
+		}
+	}
+	return paths
+}
+
+// gatewayReference returns the reference of the parentRef or backendRef of a
+// Gateway API route at path when it sets a namespace
+func (r *Resource) gatewayReference(path []interface{}, defaultKind string) []namespaceReference {
+	namespace := r.getString(joinPath(path, "namespace")...)
+	if namespace == "" {
+		return nil
+	}
+	kind := r.getString(joinPath(path, "kind")...)
+	if kind == "" {
+		kind = defaultKind
+	}
+	return []namespaceReference{{
+		namespace: namespace,
+		target:    fmt.Sprintf("the %s %s", kind, r.getString(joinPath(path, "name")...)),
+		path:      joinPath(path, "namespace"),
+	}}
+}
+
+// checkCrossNamespaceReferences fails when a resource references one in
+// another namespace, either explicitly or because the Pull Request only
+// defines the Service an Ingress routes to in another namespace. References
+// between namespaces can be allowed with globs matching from/to.
+func checkCrossNamespaceReferences(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().CrossNamespaceReferences
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, ref := range r.namespaceReferences(resources) {
+		if ref.namespace == r.Namespace() || policy.allowed(r.Namespace(), ref.namespace) {
+			continue
+		}
+		message := fmt.Sprintf("%s in the %s namespace references %s in the %s namespace.", r, r.Namespace(), ref.target, ref.namespace)
+		if ref.implicit {
+			message = fmt.Sprintf("%s routes to %s, which this Pull Request only defines in the %s namespace. Ingresses can only route to Services in their own namespace, %s.", r, ref.target, ref.namespace, r.Namespace())
+		}
+		annotations = append(annotations, r.annotation(level, crossNamespaceReferenceTitle, message, ref.path...))
+	}
+	return annotations
+}
+
+// allowed returns true when references from the namespace from to the
+// namespace to match one of the allowed from/to globs
+func (policy *KubeValidatorConfigCrossNamespaceReferences) allowed(from, to string) bool {
+	for _, glob := range policy.Allowed {
+		if matched, _ := doublestar.Match(glob, fmt.Sprintf("%s/%s", from, to)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestCrossNamespaceReferences(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		CrossNamespaceReferences: &KubeValidatorConfigCrossNamespaceReferences{
+			Allowed: []string{"*/shared"},
+		},
+	}, "fixtures/checks/namespaces/cross-namespace.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/cross-namespace.yaml"),
+		StartLine:       github.Int(26),
+		AnnotationLevel: github.String("failure"),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/cross-namespace.yaml"),
+		StartLine:       github.Int(37),
+		AnnotationLevel: github.String("failure"),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/cross-namespace.yaml"),
+		StartLine:       github.Int(60),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestCrossNamespaceReferencesIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/namespaces/cross-namespace.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
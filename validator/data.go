@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const dataKeyTitle = "Invalid data key"
+
+// dataKeyPattern matches the keys allowed in the data of ConfigMaps and
+// Secrets
+var dataKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+const maxDataKeyLength = 253
+
+// dataFields lists the fields of each kind containing keyed data
+var dataFields = map[string][]string{
+	"ConfigMap": {"data", "binaryData"},
+	"Secret":    {"data", "stringData"},
+}
+
+// checkDataKeys fails when a key in the data of a ConfigMap or Secret isn't
+// made up of alphanumeric characters, '-', '_' or '.', which the API server
+// rejects.
+func checkDataKeys(r *Resource, resources []*Resource) Annotations {
+	fields, ok := dataFields[r.Kind()]
+	if !ok {
+		return nil
+	}
+	level := r.policies().DataKeys.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, field := range fields {
+		data := stringMap(r.get(field))
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var problem string
+			switch {
+			case !dataKeyPattern.MatchString(key):
+				problem = "must only contain alphanumeric characters, '-', '_' or '.'"
+			case key == "." || key == "..":
+				problem = "can't be '.' or '..'"
+			case len(key) > maxDataKeyLength:
+				problem = fmt.Sprintf("must be no more than %d characters", maxDataKeyLength)
+			default:
+				continue
+			}
+			annotations = append(annotations, r.annotation(level, dataKeyTitle,
+				fmt.Sprintf("The key %q in the %s of %s %s.", key, field, r, problem),
+				field, key))
+		}
+	}
+	return annotations
+}
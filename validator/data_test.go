@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestDataKeys(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/data/keys.yaml")
+
+	path := github.String("fixtures/checks/data/keys.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(16), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(8), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestDataKeysCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		DataKeys: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/data/keys.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
@@ -0,0 +1,111 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const missingDisruptionBudgetTitle = "No PodDisruptionBudget"
+
+// labelSelectorMatches returns true when the matchLabels and matchExpressions
+// of selector both match labels. An empty selector matches every pod.
+func labelSelectorMatches(selector map[string]interface{}, labels map[string]string) bool {
+	for k, v := range stringMap(selector["matchLabels"]) {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	expressions, _ := selector["matchExpressions"].([]interface{})
+	for _, item := range expressions {
+		expression, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		key, _ := expression["key"].(string)
+		operator, _ := expression["operator"].(string)
+		var values []string
+		list, _ := expression["values"].([]interface{})
+		for _, v := range list {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+		value, exists := labels[key]
+		switch operator {
+		case "In":
+			if !exists || !containsString(values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && containsString(values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// disruptionBudget returns the PodDisruptionBudget in resources selecting the
+// pods of r, if any
+func (r *Resource) disruptionBudget(resources []*Resource) *Resource {
+	labels := r.podLabels()
+	for _, pdb := range resources {
+		if pdb.Kind() != "PodDisruptionBudget" || pdb.Namespace() != r.Namespace() {
+			continue
+		}
+		selector, ok := pdb.get("spec", "selector").(map[string]interface{})
+		if ok && labelSelectorMatches(selector, labels) {
+			return pdb
+		}
+	}
+	return nil
+}
+
+// checkDisruptionBudget warns when a workload of one of the configured kinds
+// isn't selected by a PodDisruptionBudget in the Pull Request, unless it's
+// allowed explicitly because its budget already exists in the cluster.
+func checkDisruptionBudget(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().DisruptionBudgets
+	if policy == nil || !containsString(policy.kinds(), r.Kind()) {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+	if policy.allowed(r.Namespace(), r.Name()) || r.disruptionBudget(resources) != nil {
+		return nil
+	}
+
+	return Annotations{r.annotation(level, missingDisruptionBudgetTitle,
+		fmt.Sprintf("No PodDisruptionBudget in this Pull Request selects the pods of %s. Add one, or allow %s/%s if its budget already exists in the cluster.", r, r.Namespace(), r.Name()),
+		"metadata", "name")}
+}
+
+// kinds returns the kinds of workloads that require a PodDisruptionBudget
+func (policy *KubeValidatorConfigDisruptionBudgets) kinds() []string {
+	if len(policy.Kinds) == 0 {
+		return []string{"Deployment"}
+	}
+	return policy.Kinds
+}
+
+// allowed returns true when namespace/name matches one of the globs of
+// workloads whose PodDisruptionBudget exists outside of the Pull Request
+func (policy *KubeValidatorConfigDisruptionBudgets) allowed(namespace, name string) bool {
+	for _, glob := range policy.Workloads {
+		if matched, _ := doublestar.Match(glob, fmt.Sprintf("%s/%s", namespace, name)); matched {
+			return true
+		}
+	}
+	return false
+}
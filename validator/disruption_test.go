@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestDisruptionBudgets(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		DisruptionBudgets: &KubeValidatorConfigDisruptionBudgets{
+			Workloads: []string{"production/legacy-*"},
+		},
+	}, "fixtures/checks/disruption/budgets.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/disruption/budgets.yaml"),
+		StartLine:       github.Int(37),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestDisruptionBudgetsIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/disruption/budgets.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+	for _, test := range []struct {
+		selector map[string]interface{}
+		want     bool
+	}{
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}}, true},
+		{map[string]interface{}{"matchLabels": map[string]interface{}{"app": "api"}}, false},
+		{map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "tier", "operator": "NotIn", "values": []interface{}{"backend"}}}}, true},
+		{map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "tier", "operator": "DoesNotExist"}}}, false},
+	} {
+		if got := labelSelectorMatches(test.selector, labels); got != test.want {
+			t.Errorf("labelSelectorMatches(%v) = %v, wanted %v", test.selector, got, test.want)
+		}
+	}
+}
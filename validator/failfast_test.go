@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestFailFastStopsAtFirstError(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/batch/jobs.yaml"))
+	if candidates.Validate().count(levelFailure) < 2 {
+		t.Fatal("expected the fixture to contain more than one error")
+	}
+
+	annotations, stopped := candidates.ValidateFailFast(true, nil)
+	if !stopped {
+		t.Error("expected validation to stop early")
+	}
+	if len(annotations) != 1 || annotations[0].GetAnnotationLevel() != levelFailure {
+		t.Fatalf("expected a single failure, got %v", annotations)
+	}
+
+	client, mux, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client}
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var opt github.CreateCheckRunOptions
+		json.NewDecoder(r.Body).Decode(&opt)
+		if opt.GetConclusion() != "failure" {
+			t.Errorf("expected the check to fail, got %s", opt.GetConclusion())
+		}
+		if !strings.Contains(opt.Output.GetSummary(), stoppedEarlySummary) {
+			t.Errorf("expected the summary to note that validation stopped early, got %s", opt.Output.GetSummary())
+		}
+		if len(opt.Output.Annotations) != 1 {
+			t.Errorf("expected a single annotation, got %d", len(opt.Output.Annotations))
+		}
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	config := &KubeValidatorConfig{stoppedEarly: stopped}
+	startedAt := time.Now()
+	if err := c.createFinalCheckRun(&startedAt, gistTestEvent(), candidates, annotations, config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailFastParsesEveryFileFirst(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/batch/jobs.yaml", "fixtures/checks/resources/invalid.yaml"))
+
+	annotations, stopped := candidates.ValidateFailFast(true, nil)
+	if !stopped || len(annotations) != 1 {
+		t.Fatalf("expected validation to stop at a single error, got %v", annotations)
+	}
+	if got := annotations[0].GetTitle(); got != parseErrorTitle {
+		t.Errorf("expected the parse error to be reported first, got %s", got)
+	}
+}
+
+func TestFailFastWithoutErrors(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/autoscaling/hpa.yaml"))
+
+	annotations, stopped := candidates.ValidateFailFast(true, nil)
+	if stopped {
+		t.Error("expected validation not to stop early without errors")
+	}
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/autoscaling/hpa.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestFailFastAppliesSchemaSetConclusion(t *testing.T) {
+	candidates := schemaSetCandidates(t, schemaSetConclusionAny, "fixtures/checks/schema-sets/configmap.yaml")
+
+	annotations, stopped := candidates.ValidateFailFast(false, nil)
+	if stopped {
+		t.Error("expected validation not to stop at the failure of a schema set when another passed")
+	}
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/schema-sets/configmap.yaml"),
+		StartLine:       github.Int(1),
+		AnnotationLevel: github.String(levelWarning),
+	})
+}
@@ -0,0 +1,180 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garethr/kubeval/kubeval"
+	"github.com/xeipuuv/gojsonschema"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	gatewayAPIGroup           = "gateway.networking.k8s.io"
+	defaultGatewayAPILocation = "https://raw.githubusercontent.com/kubernetes-sigs/gateway-api"
+	defaultGatewayAPIChannel  = "standard"
+)
+
+// gatewayAPIResources maps each Gateway API kind to the plural resource name
+// its CustomResourceDefinition is named after
+var gatewayAPIResources = map[string]string{
+	"BackendLBPolicy":  "backendlbpolicies",
+	"BackendTLSPolicy": "backendtlspolicies",
+	"Gateway":          "gateways",
+	"GatewayClass":     "gatewayclasses",
+	"GRPCRoute":        "grpcroutes",
+	"HTTPRoute":        "httproutes",
+	"ReferenceGrant":   "referencegrants",
+	"TCPRoute":         "tcproutes",
+	"TLSRoute":         "tlsroutes",
+	"UDPRoute":         "udproutes",
+}
+
+// crdFailureTTL is how long a failure to fetch a CustomResourceDefinition is
+// cached before it's fetched again
+const crdFailureTTL = time.Minute
+
+var gatewayAPIClient = &http.Client{Timeout: 30 * time.Second}
+
+// crdCache holds the result of fetching the CustomResourceDefinition at each
+// URL. Definitions are cached for the lifetime of the process, and failures
+// for crdFailureTTL.
+var crdCache = struct {
+	sync.Mutex
+	results map[string]*crdResult
+}{results: map[string]*crdResult{}}
+
+// crdResult is the CustomResourceDefinition fetched from a URL, or the reason
+// it couldn't be. done is closed once the fetch has finished.
+type crdResult struct {
+	done      chan struct{}
+	crd       map[string]interface{}
+	err       error
+	fetchedAt time.Time
+}
+
+// isGatewayAPIResource returns true for resources in the Gateway API group
+func isGatewayAPIResource(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, gatewayAPIGroup+"/")
+}
+
+// gatewayAPICRDLocation returns the URL of the CustomResourceDefinition for
+// kind in the configured Gateway API release, or false when kind isn't part of
+// the Gateway API
+func (schema *KubeValidatorConfigSchema) gatewayAPICRDLocation(kind string) (string, bool) {
+	resource, ok := gatewayAPIResources[kind]
+	if !ok {
+		return "", false
+	}
+	location := schema.GatewayAPILocation
+	if location == "" {
+		location = defaultGatewayAPILocation
+	}
+	channel := schema.GatewayAPIChannel
+	if channel == "" {
+		channel = defaultGatewayAPIChannel
+	}
+	return fmt.Sprintf("%s/%s/config/crd/%s/%s_%s.yaml", strings.TrimSuffix(location, "/"), schema.GatewayAPIVersion, channel, gatewayAPIGroup, resource), true
+}
+
+// validateGatewayAPI validates a Gateway API resource against the schema of
+// its CustomResourceDefinition in the configured Gateway API release
+func validateGatewayAPI(r *Resource, schema *KubeValidatorConfigSchema) ([]kubeval.ValidationResult, error) {
+	result := kubeval.ValidationResult{
+		FileName: r.candidate.file.GetFilename(),
+		Kind:     r.Kind(),
+	}
+	location, ok := schema.gatewayAPICRDLocation(r.Kind())
+	if !ok {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("%s %s isn't a Gateway API kind", r.APIVersion(), r.Kind())
+	}
+	crd, err := fetchCRD(location)
+	if err != nil {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("Problem loading schema from the network at %s: %s", location, err)
+	}
+	version := strings.TrimPrefix(r.APIVersion(), gatewayAPIGroup+"/")
+	openAPISchema := crdSchema(crd, version)
+	if openAPISchema == nil {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("%s %s isn't served by Gateway API %s", r.APIVersion(), r.Kind(), schema.GatewayAPIVersion)
+	}
+
+	results, err := gojsonschema.Validate(gojsonschema.NewGoLoader(openAPISchema), gojsonschema.NewGoLoader(r.object))
+	if err != nil {
+		return []kubeval.ValidationResult{result}, fmt.Errorf("Problem loading schema from %s: %s", location, err)
+	}
+	if !results.Valid() {
+		result.Errors = results.Errors()
+	}
+	return []kubeval.ValidationResult{result}, nil
+}
+
+// fetchCRD returns the CustomResourceDefinition at location, fetching it
+// unless it's cached. Each location is fetched once at a time, and the cache
+// isn't locked while fetching so that a slow location doesn't block others.
+func fetchCRD(location string) (map[string]interface{}, error) {
+	crdCache.Lock()
+	result, ok := crdCache.results[location]
+	if ok {
+		select {
+		case <-result.done:
+			ok = result.err == nil || now().Sub(result.fetchedAt) < crdFailureTTL
+		default:
+		}
+	}
+	if ok {
+		crdCache.Unlock()
+		<-result.done
+		return result.crd, result.err
+	}
+	result = &crdResult{done: make(chan struct{})}
+	crdCache.results[location] = result
+	crdCache.Unlock()
+
+	result.crd, result.err = getCRD(location)
+	result.fetchedAt = now()
+	close(result.done)
+	return result.crd, result.err
+}
+
+// getCRD fetches and parses the CustomResourceDefinition at location
+func getCRD(location string) (map[string]interface{}, error) {
+	resp, err := gatewayAPIClient.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var body interface{}
+	if err := yaml.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	crd, ok := convertToStringKeys(body).(map[string]interface{})
+	if !ok || crd["kind"] != "CustomResourceDefinition" {
+		return nil, errors.New("not a CustomResourceDefinition")
+	}
+	return crd, nil
+}
+
+// crdSchema returns the OpenAPI schema of version in crd, if it's served
+func crdSchema(crd map[string]interface{}, version string) interface{} {
+	versions, _ := valueAt(crd, []interface{}{"spec", "versions"}).([]interface{})
+	for i := range versions {
+		v := []interface{}{"spec", "versions", i}
+		if valueAt(crd, joinPath(v, "name")) != version || valueAt(crd, joinPath(v, "served")) == false {
+			continue
+		}
+		return valueAt(crd, joinPath(v, "schema", "openAPIV3Schema"))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestGatewayAPIResources(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("../fixtures/gateway-api")))
+	defer server.Close()
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/gateway-api/httproute.yaml")
+	candidates[0].schemas = []*KubeValidatorConfigSchema{
+		&KubeValidatorConfigSchema{
+			Version:            "1.29.0",
+			GatewayAPIVersion:  "v1.0.0",
+			GatewayAPILocation: server.URL,
+		},
+	}
+	candidates.allowSchemaLocations((&Context{SchemaLocations: []string{server.URL}}).schemaLocationAllowed)
+	wantAnnotations(t, candidates.Validate(),
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/gateway-api/httproute.yaml"), StartLine: github.Int(19), AnnotationLevel: github.String(levelFailure)},
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/gateway-api/httproute.yaml"), StartLine: github.Int(19), AnnotationLevel: github.String(levelFailure)},
+	)
+}
+
+func TestGatewayAPILocationMustBeAllowed(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/gateway-api/httproute.yaml")
+	candidates[0].schemas = []*KubeValidatorConfigSchema{
+		&KubeValidatorConfigSchema{
+			Version:            "1.29.0",
+			GatewayAPIVersion:  "v1.0.0",
+			GatewayAPILocation: "http://169.254.169.254/latest",
+		},
+	}
+	candidates.allowSchemaLocations((&Context{}).schemaLocationAllowed)
+
+	annotations := candidates.Validate()
+	if len(annotations) != 1 || annotations[0].GetTitle() != schemaLocationNotAllowedTitle {
+		t.Errorf("expected the Gateway API location not to be allowed, got %s", github.Stringify(annotations))
+	}
+
+	config := &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{
+		Manifests: []*KubeValidatorConfigManifest{{Glob: "*.yaml", Schemas: candidates[0].schemas}},
+	}}
+	if config.Valid() {
+		t.Error("expected a Gateway API location that isn't an https URL to be invalid")
+	}
+}
+
+func TestGatewayAPIUnservedVersion(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("../fixtures/gateway-api")))
+	defer server.Close()
+
+	schema := &KubeValidatorConfigSchema{GatewayAPIVersion: "v1.0.0", GatewayAPILocation: server.URL}
+	r := &Resource{
+		candidate: &Candidate{file: &github.CommitFile{}},
+		object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1alpha2",
+			"kind":       "HTTPRoute",
+		},
+	}
+	if _, err := validateGatewayAPI(r, schema); err == nil {
+		t.Error("expected an error validating an unserved version")
+	}
+}
+
+func TestGatewayAPICRDLocation(t *testing.T) {
+	schema := &KubeValidatorConfigSchema{GatewayAPIVersion: "v1.1.0", GatewayAPIChannel: "experimental"}
+	for kind, want := range map[string]string{
+		"HTTPRoute":        "gateway.networking.k8s.io_httproutes.yaml",
+		"GatewayClass":     "gateway.networking.k8s.io_gatewayclasses.yaml",
+		"ReferenceGrant":   "gateway.networking.k8s.io_referencegrants.yaml",
+		"BackendTLSPolicy": "gateway.networking.k8s.io_backendtlspolicies.yaml",
+	} {
+		location, ok := schema.gatewayAPICRDLocation(kind)
+		if want = defaultGatewayAPILocation + "/v1.1.0/config/crd/experimental/" + want; !ok || location != want {
+			t.Errorf("%s: expected %s, got %s", kind, want, location)
+		}
+	}
+	if _, ok := schema.gatewayAPICRDLocation("Widget"); ok {
+		t.Error("expected no location for a kind outside the Gateway API")
+	}
+}
+
+func TestFetchCRDCachesFailures(t *testing.T) {
+	defer func() { now = time.Now }()
+	fetchedAt := time.Now()
+	now = func() time.Time { return fetchedAt }
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := fetchCRD(server.URL + "/missing.yaml"); err == nil {
+			t.Error("expected an error fetching a missing definition")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the failure to be cached, got %d requests", requests)
+	}
+
+	now = func() time.Time { return fetchedAt.Add(crdFailureTTL) }
+	fetchCRD(server.URL + "/missing.yaml")
+	if requests != 2 {
+		t.Errorf("expected the definition to be fetched again once the failure expired, got %d requests", requests)
+	}
+}
+
+func TestFetchCRDDoesntBlockOtherLocations(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer slow.Close()
+	server := httptest.NewServer(http.FileServer(http.Dir("../fixtures/gateway-api")))
+	defer server.Close()
+
+	slowFetched := make(chan struct{})
+	go func() {
+		fetchCRD(slow.URL + "/slow.yaml")
+		close(slowFetched)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	fetched := make(chan error)
+	go func() {
+		_, err := fetchCRD(server.URL + "/v1.0.0/config/crd/standard/gateway.networking.k8s.io_httproutes.yaml")
+		fetched <- err
+	}()
+	select {
+	case err := <-fetched:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected fetching a definition not to wait for a slow location")
+	}
+	close(release)
+	<-slowFetched
+}
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+const reportGistFilename = "kubevalidator.md"
+
+// reportMarkdown returns the check run summary followed by every annotation
+func reportMarkdown(summary string, annotations Annotations) string {
+	lines := []string{summary, "", "### Annotations", ""}
+	for _, a := range annotations {
+		lines = append(lines, fmt.Sprintf("* `%s:%d` **%s** %s: %s", a.GetPath(), a.GetStartLine(), a.GetAnnotationLevel(), a.GetTitle(), strings.Replace(a.GetMessage(), "\n", " ", -1)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reportGistDescription identifies the Gist containing reports for the head
+// branch of a check suite
+func reportGistDescription(e *github.CheckSuiteEvent) string {
+	return fmt.Sprintf("kubevalidator report for %s/%s@%s", e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), e.CheckSuite.GetHeadBranch())
+}
+
+// uploadReportGist uploads report to a secret Gist and returns its URL. The
+// Gist created by a previous run against the same branch is updated in place.
+func (c *Context) uploadReportGist(e *github.CheckSuiteEvent, report string) (string, error) {
+	if c.DryRun {
+		return "", errors.New("Gists aren't uploaded during a dry run")
+	}
+	if c.GistGithub == nil {
+		return "", errors.New("Gists aren't uploaded without a GIST_TOKEN")
+	}
+	description := reportGistDescription(e)
+	gist := &github.Gist{
+		Description: github.String(description),
+		Public:      github.Bool(false),
+		Files: map[github.GistFilename]github.GistFile{
+			reportGistFilename: github.GistFile{Content: github.String(report)},
+		},
+	}
+
+	existing, err := c.findReportGist(description)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		gist, _, err = c.GistGithub.Gists.Edit(*c.Ctx, existing.GetID(), gist)
+		if err != nil {
+			return "", errors.Wrap(err, "Couldn't update Gist")
+		}
+		return gist.GetHTMLURL(), nil
+	}
+
+	gist, _, err = c.GistGithub.Gists.Create(*c.Ctx, gist)
+	if err != nil {
+		return "", errors.Wrap(err, "Couldn't create Gist")
+	}
+	return gist.GetHTMLURL(), nil
+}
+
+// findReportGist returns the authenticated user's Gist with description, if any
+func (c *Context) findReportGist(description string) (*github.Gist, error) {
+	opt := &github.GistListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		gists, resp, err := c.GistGithub.Gists.List(*c.Ctx, "", opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "Couldn't list Gists")
+		}
+		for _, gist := range gists {
+			if gist.GetDescription() == description {
+				return gist, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// tokenTransport authenticates requests with a personal access token
+type tokenTransport struct {
+	token string
+}
+
+// RoundTrip adds the token to the Authorization header of a copy of r
+func (t *tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	authenticated := new(http.Request)
+	*authenticated = *r
+	authenticated.Header = make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		authenticated.Header[k] = v
+	}
+	authenticated.Header.Set("Authorization", "token "+t.token)
+	return http.DefaultTransport.RoundTrip(authenticated)
+}
+
+// NewGistClient returns a client which creates Gists with a personal access
+// token
+func NewGistClient(token string) *github.Client {
+	return github.NewClient(&http.Client{Transport: &tokenTransport{token: token}})
+}
@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func gistTestEvent() *github.CheckSuiteEvent {
+	return &github.CheckSuiteEvent{
+		CheckSuite: &github.CheckSuite{
+			HeadBranch: github.String("b"),
+			HeadSHA:    github.String("abc"),
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{
+				Login: github.String("o"),
+			},
+			Name: github.String("r"),
+		},
+	}
+}
+
+// wantGistSummary asserts that the check run created links to url
+func wantGistSummary(t *testing.T, mux *http.ServeMux, url string) {
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var opt github.CreateCheckRunOptions
+		json.NewDecoder(r.Body).Decode(&opt)
+		if !strings.Contains(opt.Output.GetSummary(), url) {
+			t.Errorf("expected the summary to link to %s, got %s", url, opt.Output.GetSummary())
+		}
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+}
+
+func TestOversizedReportCreatesGist(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client, GistGithub: client}
+
+	mux.HandleFunc("/gists", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `[{"id": "2", "description": "some other gist"}]`)
+		case "POST":
+			var gist github.Gist
+			json.NewDecoder(r.Body).Decode(&gist)
+			if gist.GetPublic() {
+				t.Error("expected a secret Gist")
+			}
+			if gist.GetDescription() != "kubevalidator report for o/r@b" {
+				t.Errorf("unexpected description %q", gist.GetDescription())
+			}
+			file := gist.Files[reportGistFilename]
+			if !strings.Contains(file.GetContent(), "Duplicate container name") {
+				t.Errorf("expected the report to contain annotations, got %s", file.GetContent())
+			}
+			fmt.Fprint(w, `{"id": "1", "html_url": "https://gist.github.com/1"}`)
+		default:
+			t.Errorf("unexpected %s", r.Method)
+		}
+	})
+	wantGistSummary(t, mux, "https://gist.github.com/1")
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-names.yaml")
+	annotations := candidates.Check()
+	startedAt := time.Now()
+	if err := c.createFinalCheckRun(&startedAt, gistTestEvent(), candidates, annotations, &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{MaxSummaryLength: 10}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOversizedReportUpdatesGist(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client, GistGithub: client}
+
+	mux.HandleFunc("/gists", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id": "1", "description": "kubevalidator report for o/r@b"}]`)
+	})
+	mux.HandleFunc("/gists/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		fmt.Fprint(w, `{"id": "1", "html_url": "https://gist.github.com/1"}`)
+	})
+	wantGistSummary(t, mux, "https://gist.github.com/1")
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-names.yaml")
+	startedAt := time.Now()
+	if err := c.createFinalCheckRun(&startedAt, gistTestEvent(), candidates, candidates.Check(), &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{MaxSummaryLength: 10}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOversizedReportTruncatedWithoutGists(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client}
+
+	mux.HandleFunc("/gists", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no Gist requests without a Gist client")
+	})
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		var opt github.CreateCheckRunOptions
+		json.NewDecoder(r.Body).Decode(&opt)
+		if summary := []rune(opt.Output.GetSummary()); len(summary) != 10 || summary[9] != '…' {
+			t.Errorf("expected the summary to be truncated to 10 characters, got %q", opt.Output.GetSummary())
+		}
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-names.yaml")
+	startedAt := time.Now()
+	if err := c.createFinalCheckRun(&startedAt, gistTestEvent(), candidates, candidates.Check(), &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{MaxSummaryLength: 10}}); err != nil {
+		t.Fatal(err)
+	}
+}
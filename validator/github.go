@@ -5,6 +5,7 @@ import (
 	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
@@ -14,6 +15,7 @@ import (
 const (
 	checkRunName           = "kubevalidator"
 	initialCheckRunSummary = "Validating..."
+	stoppedEarlySummary    = "Validation stopped at the first error as `failFast` is set. Fix it and push again to find any others."
 	noMatchingFiles        = "No files to validate"
 	configPath             = ".github/kubevalidator.yaml"
 )
@@ -33,12 +35,7 @@ func (c *Context) createInitialCheckRun(e *github.CheckSuiteEvent) error {
 		},
 	}
 
-	_, _, err := c.Github.Checks.CreateCheckRun(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), checkRunOpt)
-	if err != nil {
-		log.Println(errors.Wrap(err, "Couldn't create check run"))
-		return err
-	}
-	return nil
+	return c.createCheckRun(e, checkRunOpt)
 }
 
 func (c *Context) createConfigMissingCheckRun(startedAt *time.Time, e *github.CheckSuiteEvent) error {
@@ -57,12 +54,27 @@ func (c *Context) createConfigMissingCheckRun(startedAt *time.Time, e *github.Ch
 		},
 	}
 
-	_, _, err := c.Github.Checks.CreateCheckRun(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), checkRunOpt)
-	if err != nil {
-		log.Println(errors.Wrap(err, "Couldn't create check run"))
-		return err
+	return c.createCheckRun(e, checkRunOpt)
+}
+
+// createErrorCheckRun concludes the check run when validation couldn't be
+// completed because of an internal error
+func (c *Context) createErrorCheckRun(startedAt *time.Time, e *github.CheckSuiteEvent) error {
+	checkRunOpt := github.CreateCheckRunOptions{
+		Name:        checkRunName,
+		HeadBranch:  e.CheckSuite.GetHeadBranch(),
+		HeadSHA:     e.CheckSuite.GetHeadSHA(),
+		Status:      github.String("completed"),
+		Conclusion:  github.String("failure"),
+		StartedAt:   &github.Timestamp{Time: *startedAt},
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output: &github.CheckRunOutput{
+			Title:   github.String("Internal error"),
+			Summary: github.String("kubevalidator encountered an unexpected error while validating this commit. Re-run the check to try again, and please do [reach out](https://github.com/urcomputeringpal/kubevalidator/issues/new/choose) if it keeps happening!"),
+		},
 	}
-	return nil
+
+	return c.createCheckRun(e, checkRunOpt)
 }
 
 func (c *Context) createConfigInvalidCheckRun(startedAt *time.Time, e *github.CheckSuiteEvent, annotations []*github.CheckRunAnnotation) error {
@@ -82,16 +94,13 @@ func (c *Context) createConfigInvalidCheckRun(startedAt *time.Time, e *github.Ch
 		},
 	}
 
-	_, _, err := c.Github.Checks.CreateCheckRun(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), checkRunOpt)
-	if err != nil {
-		log.Println(errors.Wrap(err, "Couldn't create check run"))
-		return err
-	}
-	return nil
+	return c.createCheckRun(e, checkRunOpt)
 }
 
-// createFinalCheckRun concludes the check run
-func (c *Context) createFinalCheckRun(startedAt *time.Time, e *github.CheckSuiteEvent, candidates Candidates, annotations []*github.CheckRunAnnotation) error {
+// createFinalCheckRun concludes the check run. Summaries longer than
+// maxSummaryLength characters are uploaded to a Gist along with the
+// annotations when Gists are enabled, and truncated otherwise.
+func (c *Context) createFinalCheckRun(startedAt *time.Time, e *github.CheckSuiteEvent, candidates Candidates, annotations []*github.CheckRunAnnotation, config *KubeValidatorConfig) error {
 	var checkRunConclusion string
 	var checkRunText string
 	var checkRunSummary string
@@ -105,27 +114,68 @@ func (c *Context) createFinalCheckRun(startedAt *time.Time, e *github.CheckSuite
 		// MVP pluralization
 		filesString := "files"
 		errorsString := "errors"
+		warningsString := "warnings"
+
+		numErrors := Annotations(annotations).count(levelFailure)
+		numWarnings := Annotations(annotations).count(levelWarning)
 
 		if numFiles == 1 {
 			filesString = "file"
 		}
 
-		if len(annotations) == 1 {
+		if numErrors == 1 {
 			errorsString = "error"
 		}
 
-		if len(annotations) > 0 {
+		if numWarnings == 1 {
+			warningsString = "warning"
+		}
+
+		if numErrors > 0 {
 			checkRunConclusion = "failure"
 		} else {
 			checkRunConclusion = "success"
 		}
-		checkRunText = fmt.Sprintf("%d %s checked, %d %s", numFiles, filesString, len(annotations), errorsString)
+		numResources, _ := candidates.resourceCounts()
+		resourcesString := "resources"
+		if numResources == 1 {
+			resourcesString = "resource"
+		}
+
+		checkRunText = fmt.Sprintf("%d %s (%d %s) checked, %d %s", numFiles, filesString, numResources, resourcesString, numErrors, errorsString)
+		if numWarnings > 0 {
+			checkRunText = fmt.Sprintf("%s, %d %s", checkRunText, numWarnings, warningsString)
+		}
+		if config.schemasOnly {
+			checkRunText = fmt.Sprintf("%s (schemas only)", checkRunText)
+		}
+		if config.stoppedEarly {
+			checkRunText = fmt.Sprintf("%s, stopped at the first error", checkRunText)
+		}
 
 		var list []string
 		for _, c := range candidates {
 			list = append(list, c.MarkdownListItem())
 		}
 		checkRunSummary = strings.Join(list, "\n")
+		if counts := candidates.resourceCountsMarkdown(); counts != "" {
+			checkRunSummary = fmt.Sprintf("%s\n\n%s", checkRunSummary, counts)
+		}
+		if sets := candidates.schemaSetsMarkdown(); sets != "" {
+			checkRunSummary = fmt.Sprintf("%s\n\n%s", checkRunSummary, sets)
+		}
+		if config.stoppedEarly {
+			checkRunSummary = fmt.Sprintf("%s\n\n%s", stoppedEarlySummary, checkRunSummary)
+		}
+		if window := config.policyWindowSummary(); window != "" {
+			checkRunSummary = fmt.Sprintf("%s\n\n%s", window, checkRunSummary)
+		}
+		if config.timings != nil {
+			checkRunSummary = fmt.Sprintf("%s\n\n%s", checkRunSummary, config.timings.markdown())
+		}
+		if maxSummaryLength := config.maxSummaryLength(); maxSummaryLength > 0 && utf8.RuneCountInString(checkRunSummary) > maxSummaryLength {
+			checkRunSummary = c.gistSummary(e, checkRunSummary, annotations, maxSummaryLength)
+		}
 	}
 
 	checkRunOpt := github.CreateCheckRunOptions{
@@ -143,6 +193,16 @@ func (c *Context) createFinalCheckRun(startedAt *time.Time, e *github.CheckSuite
 		},
 	}
 
+	return c.createCheckRun(e, checkRunOpt)
+}
+
+// createCheckRun creates a check run on the head of a check suite. During a
+// dry run it's only recorded.
+func (c *Context) createCheckRun(e *github.CheckSuiteEvent, checkRunOpt github.CreateCheckRunOptions) error {
+	if c.DryRun {
+		c.checkRuns = append(c.checkRuns, checkRunOpt)
+		return nil
+	}
 	_, _, err := c.Github.Checks.CreateCheckRun(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), checkRunOpt)
 	if err != nil {
 		log.Println(errors.Wrap(err, "Couldn't create check run"))
@@ -151,9 +211,24 @@ func (c *Context) createFinalCheckRun(startedAt *time.Time, e *github.CheckSuite
 	return nil
 }
 
+// gistSummary uploads the full report to a Gist and returns a summary linking
+// to it. The summary is truncated when the Gist can't be uploaded.
+func (c *Context) gistSummary(e *github.CheckSuiteEvent, summary string, annotations Annotations, maxSummaryLength int) string {
+	url, err := c.uploadReportGist(e, reportMarkdown(summary, annotations))
+	if err != nil {
+		log.Println(err)
+		return truncateMessage(summary, maxSummaryLength)
+	}
+	return fmt.Sprintf("This report is too large to display here. [View the full report](%s), including every annotation, in a secret Gist.", url)
+}
+
 func (c *Context) bytesForFilename(e *github.CheckSuiteEvent, f string) (*[]byte, error) {
+	return c.bytesForFilenameAtRef(e, f, e.CheckSuite.GetHeadSHA())
+}
+
+func (c *Context) bytesForFilenameAtRef(e *github.CheckSuiteEvent, f string, ref string) (*[]byte, error) {
 	fileToValidate, _, _, err := c.Github.Repositories.GetContents(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), f, &github.RepositoryContentGetOptions{
-		Ref: e.CheckSuite.GetHeadSHA(),
+		Ref: ref,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("Couldn't load %s", f))
@@ -203,14 +278,36 @@ func (c *Context) kubeValidatorConfigOrAnnotation(e *github.CheckSuiteEvent) (*K
 	return config, nil, nil
 }
 
+// pullRequestFile is a file changed by a Pull Request, including the name it
+// had before it was renamed, which the version of go-github in use doesn't
+// expose
+type pullRequestFile struct {
+	github.CommitFile
+	PreviousFilename *string `json:"previous_filename,omitempty"`
+}
+
+// changedFileList lists the files changed by the Pull Requests of the check
+// suite, recording the previous name of those that were renamed
 func (c *Context) changedFileList(e *github.CheckSuiteEvent) ([]*github.CommitFile, error) {
 	var prFiles []*github.CommitFile
 	for _, pr := range e.CheckSuite.PullRequests {
-		files, _, prListErr := c.Github.PullRequests.ListFiles(*c.Ctx, e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), pr.GetNumber(), &github.ListOptions{})
-		if prListErr != nil {
+		req, err := c.Github.NewRequest("GET", fmt.Sprintf("repos/%s/%s/pulls/%d/files", e.Repo.GetOwner().GetLogin(), e.Repo.GetName(), pr.GetNumber()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var files []*pullRequestFile
+		if _, prListErr := c.Github.Do(*c.Ctx, req, &files); prListErr != nil {
 			return nil, errors.Wrap(prListErr, "Couldn't list files")
 		}
-		prFiles = append(prFiles, files...)
+		for _, file := range files {
+			if file.PreviousFilename != nil {
+				if c.previousFilenames == nil {
+					c.previousFilenames = map[string]string{}
+				}
+				c.previousFilenames[file.GetFilename()] = *file.PreviousFilename
+			}
+			prFiles = append(prFiles, &file.CommitFile)
+		}
 	}
 	return prFiles, nil
 }
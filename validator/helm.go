@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	helmChartFile         = "Chart.yaml"
+	invalidHelmChartTitle = "Invalid Helm chart"
+)
+
+var (
+	// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+	semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+	// SemVer constraints as accepted by Helm for the versions of dependencies,
+	// such as ^1.2.0, >= 1.2, < 2 || 3.x or 1.2 - 1.4.5.
+	// https://github.com/Masterminds/semver#checking-version-constraints
+	semverConstraintVersion = `v?(?:\d+|[xX*])(?:\.(?:\d+|[xX*])){0,2}(?:-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?`
+	semverConstraintTerm    = `(?:` + semverConstraintVersion + `\s+-\s+` + semverConstraintVersion + `|(?:!=|>=|=>|<=|=<|~>|[=<>~^])?\s*` + semverConstraintVersion + `)`
+	semverConstraintRange   = semverConstraintTerm + `(?:(?:\s*,\s*|\s+)` + semverConstraintTerm + `)*`
+	semverConstraintPattern = regexp.MustCompile(`^\s*` + semverConstraintRange + `(?:\s*\|\|\s*` + semverConstraintRange + `)*\s*$`)
+
+	helmChartTypes       = []string{"application", "library"}
+	helmRepositoryPrefix = []string{"https://", "http://", "oci://", "file://", "@", "alias:"}
+)
+
+// isHelmChart returns true when the Candidate is the Chart.yaml of a Helm chart
+func (c *Candidate) isHelmChart() bool {
+	return path.Base(c.file.GetFilename()) == helmChartFile
+}
+
+// validateHelmChart checks the Chart.yaml of a Helm chart for the fields
+// required by its apiVersion and for dependencies that can't be resolved.
+// https://helm.sh/docs/topics/charts/#the-chartyaml-file
+func (c *Candidate) validateHelmChart() Annotations {
+	chart := parseResource(c, *c.bytes, 0)
+	if chart == nil {
+		return Annotations{c.helmChartAnnotation(chart, "Chart.yaml must be a YAML mapping")}
+	}
+
+	var annotations Annotations
+	apiVersion := chart.APIVersion()
+	switch apiVersion {
+	case "v1", "v2":
+	case "":
+		annotations = append(annotations, c.helmChartAnnotation(chart, "apiVersion is required"))
+	default:
+		annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("apiVersion must be v1 or v2, not %s", apiVersion), "apiVersion"))
+	}
+
+	if chart.getString("name") == "" {
+		annotations = append(annotations, c.helmChartAnnotation(chart, "name is required"))
+	}
+
+	switch version := chart.get("version").(type) {
+	case nil:
+		annotations = append(annotations, c.helmChartAnnotation(chart, "version is required"))
+	case string:
+		if !semverPattern.MatchString(version) {
+			annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("version must be a SemVer 2 version, not %s", version), "version"))
+		}
+	default:
+		annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("version must be a SemVer 2 version, not %s. Quote it so that it's read as a string.", yamlKind(version)), "version"))
+	}
+
+	if chartType := chart.getString("type"); chartType != "" {
+		if apiVersion == "v1" {
+			annotations = append(annotations, c.helmChartAnnotation(chart, "type requires apiVersion v2", "type"))
+		} else if !containsString(helmChartTypes, chartType) {
+			annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("type must be one of %s, not %s", strings.Join(helmChartTypes, ", "), chartType), "type"))
+		}
+	}
+
+	dependencies, _ := chart.get("dependencies").([]interface{})
+	if len(dependencies) > 0 && apiVersion == "v1" {
+		annotations = append(annotations, c.helmChartAnnotation(chart, "dependencies requires apiVersion v2. Use requirements.yaml with apiVersion v1.", "dependencies"))
+	}
+	for i := range dependencies {
+		for _, field := range []string{"name", "version"} {
+			if chart.get("dependencies", i, field) == nil {
+				annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("dependencies[%d].%s is required", i, field), "dependencies", i))
+			}
+		}
+		switch version := chart.get("dependencies", i, "version").(type) {
+		case nil:
+		case string:
+			if !semverConstraintPattern.MatchString(version) {
+				annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("dependencies[%d].version must be a SemVer version or range, not %s", i, version), "dependencies", i, "version"))
+			}
+		default:
+			annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("dependencies[%d].version must be a SemVer version or range, not %s. Quote it so that it's read as a string.", i, yamlKind(version)), "dependencies", i, "version"))
+		}
+		repository := chart.getString("dependencies", i, "repository")
+		if repository != "" && !hasAnyPrefix(repository, helmRepositoryPrefix) {
+			annotations = append(annotations, c.helmChartAnnotation(chart, fmt.Sprintf("dependencies[%d].repository must be a URL or a repository alias, not %s", i, repository), "dependencies", i, "repository"))
+		}
+	}
+
+	return annotations
+}
+
+func (c *Candidate) helmChartAnnotation(chart *Resource, message string, path ...interface{}) *github.CheckRunAnnotation {
+	if chart == nil {
+		chart = &Resource{candidate: c}
+	}
+	return chart.annotation(levelFailure, invalidHelmChartTitle, message, path...)
+}
+
+// yamlKind describes the type of a YAML value that isn't a string
+func yamlKind(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}, map[interface{}]interface{}:
+		return "a mapping"
+	case bool:
+		return "a boolean"
+	}
+	return "a number"
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestHelmChartMissingVersion(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/helm/missing-version/Chart.yaml")
+	annotations := candidates.Validate()
+
+	path := github.String("fixtures/checks/helm/missing-version/Chart.yaml")
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(1), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(4), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(8), AnnotationLevel: github.String("failure")},
+	)
+	if len(annotations) > 0 && annotations[0].GetMessage() != "version is required" {
+		t.Errorf("expected missing version, got %s", annotations[0].GetMessage())
+	}
+}
+
+func TestValidHelmChart(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/helm/valid/Chart.yaml")
+	wantAnnotations(t, candidates.Validate())
+}
+
+func TestHelmChartVersions(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/helm/numeric-version/Chart.yaml")
+	annotations := candidates.Validate()
+
+	path := github.String("fixtures/checks/helm/numeric-version/Chart.yaml")
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(3), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(6), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(9), AnnotationLevel: github.String("failure")},
+	)
+	for _, annotation := range annotations {
+		if annotation.GetStartLine() != 6 && !strings.Contains(annotation.GetMessage(), "not a number") {
+			t.Errorf("expected the message to say a number was given, got %s", annotation.GetMessage())
+		}
+	}
+}
+
+func TestSemverConstraintPattern(t *testing.T) {
+	for _, constraint := range []string{"10.x.x", "^1.2.3", ">=1.0.0, <2.0.0", ">= 1.2 < 2", "~1.2 || ^2", "1.2 - 1.4.5", "*", "1.2.3-beta.1", "v2"} {
+		if !semverConstraintPattern.MatchString(constraint) {
+			t.Errorf("expected %q to be a valid constraint", constraint)
+		}
+	}
+	for _, constraint := range []string{"", "latest", "1.2.3.4", ">>1", "1..2", "^", "1.2 ||"} {
+		if semverConstraintPattern.MatchString(constraint) {
+			t.Errorf("expected %q to be an invalid constraint", constraint)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const ignorePath = ".kubevalidatorignore"
+
+// ignorePattern is a single line of a .kubevalidatorignore file
+type ignorePattern struct {
+	glob    string
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreFile contains patterns using .gitignore syntax. Matching files aren't
+// validated.
+type ignoreFile []ignorePattern
+
+// parseIgnoreFile parses the contents of a .kubevalidatorignore file
+func parseIgnoreFile(b []byte) ignoreFile {
+	var patterns ignoreFile
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pattern ignorePattern
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			// Patterns containing a slash are relative to the root
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		pattern.glob = line
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// ignored returns true when the last pattern matching filename, or one of its
+// parent directories, isn't negated.
+func (i ignoreFile) ignored(filename string) bool {
+	ignored := false
+	for _, pattern := range i {
+		if pattern.matches(filename) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+func (p ignorePattern) matches(filename string) bool {
+	if !p.dirOnly {
+		if matched, _ := doublestar.Match(p.glob, filename); matched {
+			return true
+		}
+	}
+	for dir := path.Dir(filename); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matched, _ := doublestar.Match(p.glob, dir); matched {
+			return true
+		}
+	}
+	return false
+}
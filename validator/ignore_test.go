@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestIgnoreFileSkipsMatchingCandidates(t *testing.T) {
+	config := &KubeValidatorConfig{
+		Spec: &KubeValidatorConfigSpec{
+			Manifests: []*KubeValidatorConfigManifest{{
+				Glob: "**/*.yaml",
+			}},
+		},
+		ignore: parseIgnoreFile([]byte("# generated manifests\nvendor/\n/config/*-values.yaml\nskip.yaml\n!config/keep/skip.yaml\n")),
+	}
+
+	filenames := map[string]bool{
+		"deployment.yaml":                true,
+		"vendor/chart/deployment.yaml":   false,
+		"config/prod-values.yaml":        false,
+		"config/nested/prod-values.yaml": true,
+		"skip.yaml":                      false,
+		"config/other/skip.yaml":         false,
+		"config/keep/skip.yaml":          true,
+	}
+	var files []*github.CommitFile
+	for filename := range filenames {
+		files = append(files, &github.CommitFile{Filename: github.String(filename)})
+	}
+
+	matched := map[string]bool{}
+	for _, candidate := range config.matchingCandidates(&Context{}, files) {
+		matched[candidate.file.GetFilename()] = true
+	}
+	for filename, want := range filenames {
+		if matched[filename] != want {
+			t.Errorf("%s: expected matched to be %t", filename, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	unapprovedImageTitle        = "Image not approved"
+	imagePolicyUnavailableTitle = "Image policy unavailable"
+)
+
+// imagePolicyTTL is how long an image policy is cached before it's loaded
+// again, so that changes to a central policy are picked up without a restart
+const imagePolicyTTL = 5 * time.Minute
+
+var imagePolicyClient = &http.Client{Timeout: 30 * time.Second}
+
+var errImagePolicySourceNotAllowed = errors.New("image policy source not allowed")
+
+// imagePolicyCache holds the result of loading the image policy at each
+// source, including failures, so that each source is loaded at most once per
+// imagePolicyTTL
+var imagePolicyCache = struct {
+	sync.Mutex
+	results map[string]*imagePolicyResult
+}{results: map[string]*imagePolicyResult{}}
+
+// imagePolicy is the file listing the images containers may use
+type imagePolicy struct {
+	Images []imagePolicyEntry `yaml:"images"`
+}
+
+// imagePolicyResult is the image policy loaded from a source, or the reason
+// it couldn't be
+type imagePolicyResult struct {
+	policy   *imagePolicy
+	err      error
+	loadedAt time.Time
+}
+
+// imagePolicyEntry approves the images of the repositories matching a glob.
+// Images must also use a tag matching one of Tags and one of Digests when
+// they're set, and be referenced by digest when RequireDigest is set.
+type imagePolicyEntry struct {
+	Repository    string   `yaml:"repository"`
+	Tags          []string `yaml:"tags,omitempty"`
+	Digests       []string `yaml:"digests,omitempty"`
+	RequireDigest bool     `yaml:"requireDigest,omitempty"`
+}
+
+// imageReference is an image split into its repository, tag and digest
+type imageReference struct {
+	repository string
+	tag        string
+	digest     string
+}
+
+// parseImageReference splits image into its repository, tag and digest.
+// Images referenced by neither a tag nor a digest use the latest tag.
+func parseImageReference(image string) imageReference {
+	var ref imageReference
+	if i := strings.Index(image, "@"); i >= 0 {
+		ref.digest = image[i+1:]
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		ref.tag = image[i+1:]
+		image = image[:i]
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+	}
+	ref.repository = normalizeRepository(image)
+	return ref
+}
+
+// normalizeRepository qualifies repositories on Docker Hub with its registry,
+// and official images with the library namespace, like the container runtime
+// does when pulling them
+func normalizeRepository(repository string) string {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 1 {
+		return "docker.io/library/" + repository
+	}
+	if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		return "docker.io/" + repository
+	}
+	return repository
+}
+
+// allows returns true when ref satisfies every constraint of the entry
+func (entry imagePolicyEntry) allows(ref imageReference) bool {
+	if matched, _ := doublestar.Match(normalizeRepository(entry.Repository), ref.repository); !matched {
+		return false
+	}
+	if entry.RequireDigest && ref.digest == "" {
+		return false
+	}
+	if len(entry.Digests) > 0 && !containsString(entry.Digests, ref.digest) {
+		return false
+	}
+	if len(entry.Tags) == 0 {
+		return true
+	}
+	for _, glob := range entry.Tags {
+		if matched, _ := doublestar.Match(glob, ref.tag); matched && ref.tag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns true when image matches any entry of the policy
+func (policy *imagePolicy) allows(image string) bool {
+	ref := parseImageReference(image)
+	for _, entry := range policy.Images {
+		if entry.allows(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadImagePolicy loads the image policy at source, an http(s) URL or a local
+// path. Results, including failures, are cached for imagePolicyTTL. The cache
+// isn't locked while loading so that a slow source doesn't block others.
+func loadImagePolicy(source string) *imagePolicyResult {
+	imagePolicyCache.Lock()
+	result, ok := imagePolicyCache.results[source]
+	imagePolicyCache.Unlock()
+	if ok && now().Sub(result.loadedAt) < imagePolicyTTL {
+		return result
+	}
+
+	result = &imagePolicyResult{loadedAt: now()}
+	var b []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		b, result.err = fetchImagePolicy(source)
+	} else {
+		b, result.err = ioutil.ReadFile(source)
+	}
+	if result.err == nil {
+		result.policy = &imagePolicy{}
+		result.err = yaml.Unmarshal(b, result.policy)
+	}
+
+	imagePolicyCache.Lock()
+	imagePolicyCache.results[source] = result
+	imagePolicyCache.Unlock()
+	return result
+}
+
+// loadImagePolicies loads the image policy of each candidate once, before any
+// resource is checked. Sources for which allowed returns false aren't loaded.
+func (c *Candidates) loadImagePolicies(allowed func(source string) bool) {
+	results := map[string]*imagePolicyResult{}
+	for _, candidate := range *c {
+		config := candidate.getPolicies().ImagePolicy
+		if config == nil {
+			continue
+		}
+		result, ok := results[config.Source]
+		if !ok {
+			if allowed(config.Source) {
+				result = loadImagePolicy(config.Source)
+				if result.err != nil {
+					log.Printf("Couldn't load the image policy at %s: %s\n", config.Source, result.err)
+				}
+			} else {
+				result = &imagePolicyResult{err: errImagePolicySourceNotAllowed}
+			}
+			results[config.Source] = result
+		}
+		candidate.imagePolicy = result
+	}
+}
+
+// imagePolicySourceAllowed returns true when source matches one of the globs
+// of image policy sources the operator of the GitHub App allows repositories
+// to use. No sources are allowed by default, as configurations could
+// otherwise read files on, or make requests from, the server.
+func (c *Context) imagePolicySourceAllowed(source string) bool {
+	for _, glob := range c.ImagePolicySources {
+		if matched, _ := doublestar.Match(glob, source); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchImagePolicy fetches the image policy at location
+func fetchImagePolicy(location string) ([]byte, error) {
+	resp, err := imagePolicyClient.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// checkImagePolicy fails when the image of a container doesn't match any of
+// the images approved by the configured image policy file. Workloads are
+// annotated once when the policy couldn't be loaded. The reason is only
+// logged, as it may include the contents of the source.
+func checkImagePolicy(r *Resource, resources []*Resource) Annotations {
+	config := r.policies().ImagePolicy
+	if config == nil {
+		return nil
+	}
+	level := config.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+	containers := r.containers()
+	if len(containers) == 0 {
+		return nil
+	}
+
+	result := r.candidate.imagePolicy
+	if result == nil || result.err != nil {
+		reason := "couldn't be loaded"
+		if result != nil && result.err == errImagePolicySourceNotAllowed {
+			reason = "isn't one of the sources allowed by the operator of kubevalidator"
+		}
+		return Annotations{r.annotation(level, imagePolicyUnavailableTitle,
+			fmt.Sprintf("The images of %s couldn't be checked because the image policy at %s %s.", r, config.Source, reason),
+			containers[0].path...)}
+	}
+
+	var annotations Annotations
+	for _, c := range containers {
+		image := c.image()
+		if image == "" || result.policy.allows(image) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, unapprovedImageTitle,
+			fmt.Sprintf("Container %s of %s uses %s, which isn't approved by the image policy at %s.", c.name(), r, image, config.Source),
+			joinPath(c.path, "image")...))
+	}
+	return annotations
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	imageDigestTitle     = "Image not pinned to a digest"
+	imagePullPolicyTitle = "Image pull policy not set"
+)
+
+var imageDigest = regexp.MustCompile(`@sha256:[0-9a-f]{64}$`)
+
+// image returns the image of a container
+func (c *container) image() string {
+	s, _ := c.object["image"].(string)
+	return s
+}
+
+// checkRequireDigest fails when the image of a container isn't pinned to a
+// sha256 digest. Tags can be moved to point at different images, digests
+// can't.
+func checkRequireDigest(r *Resource, resources []*Resource) Annotations {
+	rule := r.policies().RequireDigest
+	if rule == nil {
+		return nil
+	}
+	level := rule.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		image := c.image()
+		if image == "" || imageDigest.MatchString(image) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, imageDigestTitle,
+			fmt.Sprintf("Container %s of %s uses %s, which isn't pinned to a digest such as %s@sha256:….", c.name(), r, image, image),
+			joinPath(c.path, "image")...))
+	}
+	return annotations
+}
+
+// pinned returns true when the image of a container is referenced by digest or
+// by a tag other than latest
+func (c *container) pinned() bool {
+	image := c.image()
+	if imageDigest.MatchString(image) {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i >= 0 && name[i+1:] != "latest"
+}
+
+// checkImagePullPolicy fails when a container doesn't set imagePullPolicy, as
+// the default depends on the tag of its image. When configured, the policy
+// must also match the one required for pinned images or for images using the
+// latest tag.
+func checkImagePullPolicy(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().ImagePullPolicies
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.containers() {
+		pullPolicy, _ := c.get("imagePullPolicy").(string)
+		if pullPolicy == "" {
+			annotations = append(annotations, r.annotation(level, imagePullPolicyTitle,
+				fmt.Sprintf("Container %s of %s doesn't set imagePullPolicy, so it depends on the tag of %s.", c.name(), r, c.image()),
+				c.path...))
+			continue
+		}
+
+		required, style := policy.Latest, "using the latest tag"
+		if c.pinned() {
+			required, style = policy.Pinned, "pinned to a tag or digest"
+		}
+		if required != "" && pullPolicy != required {
+			annotations = append(annotations, r.annotation(level, imagePullPolicyTitle,
+				fmt.Sprintf("Container %s of %s uses the %s imagePullPolicy, but images %s must use %s.", c.name(), r, pullPolicy, style, required),
+				joinPath(c.path, "imagePullPolicy")...))
+		}
+	}
+	return annotations
+}
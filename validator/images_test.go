@@ -0,0 +1,176 @@
+package validator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestRequireDigest(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RequireDigest: &KubeValidatorConfigRule{},
+	}, "fixtures/checks/images/digests.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/digests.yaml"),
+		StartLine:       github.Int(11),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestRequireDigestDisabledByDefault(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/images/digests.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestImagePullPolicy(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImagePullPolicies: &KubeValidatorConfigImagePullPolicies{},
+	}, "fixtures/checks/images/pull-policy.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/pull-policy.yaml"),
+		StartLine:       github.Int(7),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestImagePullPolicyPerTagStyle(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImagePullPolicies: &KubeValidatorConfigImagePullPolicies{Pinned: "IfNotPresent", Latest: "Always"},
+	}, "fixtures/checks/images/pull-policy.yaml")
+
+	path := github.String("fixtures/checks/images/pull-policy.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(12), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(18), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(7), AnnotationLevel: github.String("failure")},
+	)
+}
+
+// anyImagePolicySource allows image policies to be loaded from any source
+func anyImagePolicySource(string) bool {
+	return true
+}
+
+func TestImagePolicy(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImagePolicy: &KubeValidatorConfigImagePolicy{Source: "../fixtures/checks/images/policy.yaml"},
+	}, "fixtures/checks/images/approved.yaml")
+	candidates.loadImagePolicies(anyImagePolicySource)
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/approved.yaml"),
+		StartLine:       github.Int(12),
+		AnnotationLevel: github.String("failure"),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/approved.yaml"),
+		StartLine:       github.Int(14),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestImagePolicyFromURL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeFile(w, r, "../fixtures/checks/images/policy.yaml")
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+			ImagePolicy: &KubeValidatorConfigImagePolicy{Source: server.URL + "/policy.yaml"},
+		}, "fixtures/checks/images/approved.yaml")
+		candidates.loadImagePolicies(anyImagePolicySource)
+		if got := len(candidates.Check()); got != 2 {
+			t.Errorf("expected 2 annotations, got %d", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the policy to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestImagePolicyFailuresAreCached(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+			ImagePolicy: &KubeValidatorConfigImagePolicy{Source: server.URL + "/policy.yaml"},
+		}, "fixtures/checks/images/approved.yaml", "fixtures/checks/images/digests.yaml")
+		candidates.loadImagePolicies(anyImagePolicySource)
+		if got := len(candidates.Check()); got != 2 {
+			t.Errorf("expected each workload to be annotated once, got %d annotations", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the failure to be cached, got %d requests", requests)
+	}
+}
+
+func TestImagePolicySourceNotAllowed(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeFile(w, r, "../fixtures/checks/images/policy.yaml")
+	}))
+	defer server.Close()
+
+	c := &Context{ImagePolicySources: []string{"https://policies.example.com/**"}}
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImagePolicy: &KubeValidatorConfigImagePolicy{Source: server.URL + "/policy.yaml"},
+	}, "fixtures/checks/images/approved.yaml")
+	candidates.loadImagePolicies(c.imagePolicySourceAllowed)
+
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/approved.yaml"),
+		StartLine:       github.Int(7),
+		AnnotationLevel: github.String("failure"),
+	})
+	if !strings.Contains(annotations[0].GetMessage(), "isn't one of the sources allowed") {
+		t.Errorf("expected the source not to be allowed, got %s", annotations[0].GetMessage())
+	}
+	if requests != 0 {
+		t.Errorf("expected the source not to be requested, got %d requests", requests)
+	}
+	if !c.imagePolicySourceAllowed("https://policies.example.com/images/policy.yaml") {
+		t.Error("expected sources matching a glob to be allowed")
+	}
+}
+
+func TestImagePolicyUnavailable(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImagePolicy: &KubeValidatorConfigImagePolicy{Source: "../fixtures/checks/images/missing.yaml"},
+	}, "fixtures/checks/images/approved.yaml")
+	candidates.loadImagePolicies(anyImagePolicySource)
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/images/approved.yaml"),
+		StartLine:       github.Int(7),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestParseImageReference(t *testing.T) {
+	for image, want := range map[string]imageReference{
+		"nginx":                      {repository: "docker.io/library/nginx", tag: "latest"},
+		"example/app:1.0":            {repository: "docker.io/example/app", tag: "1.0"},
+		"localhost:5000/app":         {repository: "localhost:5000/app", tag: "latest"},
+		"ghcr.io/acme/app@sha256:ab": {repository: "ghcr.io/acme/app", digest: "sha256:ab"},
+	} {
+		if got := parseImageReference(image); got != want {
+			t.Errorf("parseImageReference(%q) = %+v, want %+v", image, got, want)
+		}
+	}
+}
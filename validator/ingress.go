@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ingressCollisionTitle = "Conflicting Ingress route"
+	ingressTLSTitle       = "Ingress TLS not configured"
+	ingressIssuerTitle    = "Certificate issuer not approved"
+)
+
+// issuerAnnotations are the annotations with which cert-manager is asked to
+// issue certificates for an Ingress
+var issuerAnnotations = []string{
+	"cert-manager.io/cluster-issuer",
+	"cert-manager.io/issuer",
+	"certmanager.k8s.io/cluster-issuer",
+	"certmanager.k8s.io/issuer",
+}
+
+// ingressRoute is a host, path and path type served by an Ingress, or its
+// default backend
+type ingressRoute struct {
+	path  []interface{}
+	route string
+	key   string
+}
+
+// ingressClass returns the class of an Ingress from either ingressClassName
+// or the legacy kubernetes.io/ingress.class annotation
+func (r *Resource) ingressClass() string {
+	if class := r.getString("spec", "ingressClassName"); class != "" {
+		return class
+	}
+	return r.getString("metadata", "annotations", "kubernetes.io/ingress.class")
+}
+
+// ingressRoutes returns the routes served by an Ingress. Routes only collide
+// with routes of Ingresses of the same class.
+func (r *Resource) ingressRoutes() []ingressRoute {
+	if r.Kind() != "Ingress" {
+		return nil
+	}
+	class := r.ingressClass()
+
+	var routes []ingressRoute
+	for _, field := range []string{"defaultBackend", "backend"} {
+		if r.get("spec", field) != nil {
+			routes = append(routes, ingressRoute{
+				path:  []interface{}{"spec", field},
+				route: "the default backend",
+				key:   fmt.Sprintf("%s default", class),
+			})
+		}
+	}
+
+	rules, _ := r.get("spec", "rules").([]interface{})
+	for i := range rules {
+		host := r.getString("spec", "rules", i, "host")
+		paths, _ := r.get("spec", "rules", i, "http", "paths").([]interface{})
+		for j := range paths {
+			pathPath := []interface{}{"spec", "rules", i, "http", "paths", j}
+			path := r.getString(joinPath(pathPath, "path")...)
+			if path == "" {
+				path = "/"
+			}
+			pathType := r.getString(joinPath(pathPath, "pathType")...)
+			if pathType == "" {
+				pathType = "ImplementationSpecific"
+			}
+			if pathType == "Prefix" && path != "/" {
+				path = strings.TrimSuffix(path, "/")
+			}
+			routes = append(routes, ingressRoute{
+				path:  joinPath(pathPath, "path"),
+				route: fmt.Sprintf("%s%s (%s)", host, path, pathType),
+				key:   fmt.Sprintf("%s %s %s %s", class, host, pathType, path),
+			})
+		}
+	}
+	return routes
+}
+
+// checkIngressCollisions warns when more than one Ingress of the same class
+// serves the same host, path and path type, or defines a default backend, as
+// the controller will pick one of them unpredictably.
+func checkIngressCollisions(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "Ingress" {
+		return nil
+	}
+	level := r.policies().IngressCollisions.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, route := range r.ingressRoutes() {
+		var others []string
+		for _, other := range resources {
+			if other == r {
+				continue
+			}
+			for _, otherRoute := range other.ingressRoutes() {
+				if otherRoute.key == route.key {
+					others = append(others, other.String())
+					break
+				}
+			}
+		}
+		if len(others) > 0 {
+			annotations = append(annotations, r.annotation(level, ingressCollisionTitle,
+				fmt.Sprintf("%s serves %s, as does %s. Requests will be routed to one of them unpredictably.", r, route.route, strings.Join(others, ", ")),
+				route.path...))
+		}
+	}
+	return annotations
+}
+
+// checkIngressTLS fails when TLS is required and an Ingress doesn't configure
+// spec.tls, or when it asks cert-manager for a certificate from an issuer that
+// isn't approved.
+func checkIngressTLS(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().IngressTLS
+	if policy == nil || r.Kind() != "Ingress" {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	if tls, _ := r.get("spec", "tls").([]interface{}); policy.Required && len(tls) == 0 {
+		annotations = append(annotations, r.annotation(level, ingressTLSTitle,
+			fmt.Sprintf("%s doesn't configure spec.tls, which is required.", r),
+			"spec"))
+	}
+	if len(policy.Issuers) == 0 {
+		return annotations
+	}
+	for _, key := range issuerAnnotations {
+		issuer := r.getString("metadata", "annotations", key)
+		if issuer == "" || containsString(policy.Issuers, issuer) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, ingressIssuerTitle,
+			fmt.Sprintf("%s requests a certificate from %s, which isn't an approved issuer. Approved issuers: %s", r, issuer, strings.Join(policy.Issuers, ", ")),
+			"metadata", "annotations", key))
+	}
+	return annotations
+}
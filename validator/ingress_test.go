@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestIngressCollisions(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/ingress/collisions.yaml")
+
+	path := github.String("fixtures/checks/ingress/collisions.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(11), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(29), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestIngressCollisionsCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		IngressCollisions: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/ingress/collisions.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestIngressTLS(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		IngressTLS: &KubeValidatorConfigIngressTLS{
+			Required: true,
+			Issuers:  []string{"letsencrypt-production"},
+		},
+	}, "fixtures/checks/ingress/tls.yaml")
+
+	path := github.String("fixtures/checks/ingress/tls.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(24), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(5), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestIngressTLSIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/ingress/tls.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
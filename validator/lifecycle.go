@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const terminationGracePeriodTitle = "Short termination grace period"
+
+// defaultTerminationGracePeriodSeconds is used by Kubernetes when a pod spec
+// doesn't set terminationGracePeriodSeconds
+const defaultTerminationGracePeriodSeconds = 30
+
+var sleepCommand = regexp.MustCompile(`(?:^|[\s;&|])sleep\s+(\d+)`)
+
+// intValue returns v as an int when it's an integer
+func intValue(v interface{}) (int, bool) {
+	switch i := v.(type) {
+	case int:
+		return i, true
+	case int64:
+		return int(i), true
+	case float64:
+		return int(i), i == float64(int(i))
+	}
+	return 0, false
+}
+
+// preStopSeconds returns the number of seconds the preStop hook of c sleeps
+// for, when that can be determined
+func (c *container) preStopSeconds() (int, []interface{}, bool) {
+	if seconds, ok := intValue(c.get("lifecycle", "preStop", "sleep", "seconds")); ok {
+		return seconds, []interface{}{"lifecycle", "preStop", "sleep", "seconds"}, true
+	}
+	command, _ := c.get("lifecycle", "preStop", "exec", "command").([]interface{})
+	var words []string
+	for _, word := range command {
+		words = append(words, fmt.Sprintf("%v", word))
+	}
+	match := sleepCommand.FindStringSubmatch(strings.Join(words, " "))
+	if match == nil {
+		return 0, nil, false
+	}
+	seconds, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, nil, false
+	}
+	return seconds, []interface{}{"lifecycle", "preStop", "exec", "command"}, true
+}
+
+// checkTerminationGracePeriod warns when the terminationGracePeriodSeconds of
+// a pod is below the configured minimum, or doesn't leave time for a preStop
+// hook to finish sleeping before the pod is killed.
+func checkTerminationGracePeriod(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().TerminationGracePeriods
+	if policy == nil {
+		return nil
+	}
+	spec, ok := r.podSpecPath()
+	if !ok {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	gracePath := joinPath(spec, "terminationGracePeriodSeconds")
+	grace, set := intValue(r.get(gracePath...))
+	if !set {
+		grace = defaultTerminationGracePeriodSeconds
+		gracePath = spec
+	}
+	if grace < policy.MinimumSeconds {
+		annotations = append(annotations, r.annotation(level, terminationGracePeriodTitle,
+			fmt.Sprintf("%s has a termination grace period of %d seconds, which is less than the minimum of %d.", r, grace, policy.MinimumSeconds),
+			gracePath...))
+	}
+
+	for _, c := range r.containers() {
+		seconds, path, ok := c.preStopSeconds()
+		if !ok || seconds < grace {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, terminationGracePeriodTitle,
+			fmt.Sprintf("The preStop hook of container %s sleeps for %d seconds, but %s is killed after %d seconds.", c.name(), seconds, r, grace),
+			joinPath(c.path, path...)...))
+	}
+	return annotations
+}
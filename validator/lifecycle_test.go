@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestTerminationGracePeriods(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		TerminationGracePeriods: &KubeValidatorConfigTerminationGracePeriods{MinimumSeconds: 30},
+	}, "fixtures/checks/lifecycle/grace-period.yaml")
+
+	path := github.String("fixtures/checks/lifecycle/grace-period.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(14), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(38), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestTerminationGracePeriodsDisabledByDefault(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/lifecycle/grace-period.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
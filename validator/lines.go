@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"strings"
+)
+
+// lineToken is a mapping key or sequence item found while scanning a YAML
+// document. Only block style YAML is tokenized; flow style collections are
+// attributed to the key that contains them.
+type lineToken struct {
+	line   int
+	column int
+	item   bool
+	key    string
+}
+
+// tokenizeYAML scans a single YAML document line by line, recording where each
+// key and sequence item begins.
+func tokenizeYAML(b []byte) []lineToken {
+	var tokens []lineToken
+	blockScalarColumn := -1
+	for i, text := range strings.Split(string(b), "\n") {
+		text = strings.TrimRight(text, "\r")
+		trimmed := strings.TrimLeft(text, " ")
+		column := len(text) - len(trimmed)
+
+		if blockScalarColumn >= 0 {
+			if trimmed == "" || column > blockScalarColumn {
+				continue
+			}
+			blockScalarColumn = -1
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		for trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			tokens = append(tokens, lineToken{line: i + 1, column: column, item: true})
+			rest := strings.TrimLeft(trimmed[1:], " ")
+			column += len(trimmed) - len(rest)
+			trimmed = rest
+		}
+
+		key, value, ok := splitYAMLKey(trimmed)
+		if !ok {
+			continue
+		}
+		tokens = append(tokens, lineToken{line: i + 1, column: column, key: key})
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockScalarColumn = column
+		}
+	}
+	return tokens
+}
+
+// splitYAMLKey splits a line of the form `key: value` into its key and value
+func splitYAMLKey(s string) (string, string, bool) {
+	if s == "" || strings.ContainsAny(s[:1], "[{#&*!|>%@`") {
+		return "", "", false
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			return "", "", false
+		}
+		rest := s[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return s[1 : end+1], strings.TrimSpace(rest[1:]), true
+	}
+	if strings.HasSuffix(s, ":") && !strings.Contains(s, ": ") {
+		return s[:len(s)-1], "", true
+	}
+	index := strings.Index(s, ": ")
+	if index < 0 {
+		return "", "", false
+	}
+	return s[:index], strings.TrimSpace(s[index+2:]), true
+}
+
+// lineForPath returns the line of the deepest element of path that could be
+// found in tokens. Path elements are either string keys or int indexes. Line 1
+// is returned when not even the first element could be found.
+func lineForPath(tokens []lineToken, path []interface{}) int {
+	line := 1
+	lo, hi := 0, len(tokens)
+	for _, element := range path {
+		if lo >= hi {
+			break
+		}
+		column := tokens[lo].column
+		found := -1
+		switch e := element.(type) {
+		case string:
+			for i := lo; i < hi; i++ {
+				if tokens[i].column == column && !tokens[i].item && tokens[i].key == e {
+					found = i
+					break
+				}
+			}
+		case int:
+			n := 0
+			for i := lo; i < hi; i++ {
+				if tokens[i].column == column && tokens[i].item {
+					if n == e {
+						found = i
+						break
+					}
+					n++
+				}
+			}
+		}
+		if found < 0 {
+			break
+		}
+
+		line = tokens[found].line
+		lo, hi = found+1, scopeEnd(tokens, found, hi)
+	}
+	return line
+}
+
+// scopeEnd returns the index of the first token after tokens[start] which is
+// not nested within it.
+func scopeEnd(tokens []lineToken, start int, hi int) int {
+	parent := tokens[start]
+	for i := start + 1; i < hi; i++ {
+		t := tokens[i]
+		if t.column < parent.column {
+			return i
+		}
+		if t.column == parent.column && (parent.item || !t.item) {
+			return i
+		}
+	}
+	return hi
+}
+
+// duplicateRootKey returns the first top-level key of a document which occurs
+// more than once, and the line of its second occurrence. The YAML parser keeps
+// only the last value of duplicate keys, so a document containing several
+// resources that aren't separated by --- is parsed as a mix of them.
+func duplicateRootKey(tokens []lineToken) (string, int, bool) {
+	if len(tokens) == 0 {
+		return "", 0, false
+	}
+	column := tokens[0].column
+	seen := map[string]bool{}
+	for _, t := range tokens {
+		if t.column != column || t.item {
+			continue
+		}
+		if seen[t.key] {
+			return t.key, t.line, true
+		}
+		seen[t.key] = true
+	}
+	return "", 0, false
+}
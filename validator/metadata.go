@@ -0,0 +1,219 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const metadataKeyTitle = "Invalid metadata key"
+const metadataValueTitle = "Invalid label value"
+const deprecatedAnnotationTitle = "Deprecated annotation"
+const recommendedLabelsTitle = "Missing recommended labels"
+const exportedFieldTitle = "Exported runtime field"
+
+// exportedFields are set by the API server rather than authors, and are found
+// in manifests exported with kubectl get -o yaml
+var exportedFields = [][]interface{}{
+	{"metadata", "ownerReferences"},
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "selfLink"},
+	{"metadata", "managedFields"},
+	{"status"},
+}
+
+// recommendedLabels are the labels Kubernetes recommends applying to every
+// workload, without their app.kubernetes.io/ prefix
+var recommendedLabels = []string{"name", "instance", "version", "component", "part-of", "managed-by"}
+
+// qualifiedNamePattern matches the name part of label and annotation keys, and
+// label values
+var qualifiedNamePattern = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+
+// dnsSubdomainPattern matches the optional prefix of label and annotation keys
+var dnsSubdomainPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+const maxQualifiedNameLength = 63
+const maxDNSSubdomainLength = 253
+
+// qualifiedNameProblem describes why a label or annotation key isn't a valid
+// qualified name, or returns an empty string if it is
+func qualifiedNameProblem(key string) string {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		switch {
+		case prefix == "":
+			return "has an empty prefix"
+		case len(prefix) > maxDNSSubdomainLength:
+			return fmt.Sprintf("has a prefix longer than %d characters", maxDNSSubdomainLength)
+		case !dnsSubdomainPattern.MatchString(prefix):
+			return "has a prefix which isn't a lowercase DNS subdomain"
+		}
+	}
+	switch {
+	case name == "":
+		return "has an empty name"
+	case len(name) > maxQualifiedNameLength:
+		return fmt.Sprintf("has a name of %d characters, longer than the maximum of %d", len(name), maxQualifiedNameLength)
+	case !qualifiedNamePattern.MatchString(name):
+		return "has a name which doesn't consist of alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric character"
+	}
+	return ""
+}
+
+// labelValueProblem describes why a label value isn't valid, or returns an
+// empty string if it is
+func labelValueProblem(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case len(value) > maxQualifiedNameLength:
+		return fmt.Sprintf("is %d characters, longer than the maximum of %d", len(value), maxQualifiedNameLength)
+	case !qualifiedNamePattern.MatchString(value):
+		return "doesn't consist of alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric character"
+	}
+	return ""
+}
+
+// checkMetadataKeys fails when the keys of the labels or annotations of a
+// resource, or the values of its labels, don't have the format required by
+// the API server.
+func checkMetadataKeys(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().MetadataKeys.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, field := range []string{"labels", "annotations"} {
+		metadata := stringMap(r.get("metadata", field))
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if problem := qualifiedNameProblem(key); problem != "" {
+				annotations = append(annotations, r.annotation(level, metadataKeyTitle,
+					fmt.Sprintf("The key %q in the %s of %s %s.", key, field, r, problem),
+					"metadata", field, key))
+				continue
+			}
+			if field != "labels" {
+				continue
+			}
+			if problem := labelValueProblem(metadata[key]); problem != "" {
+				annotations = append(annotations, r.annotation(level, metadataValueTitle,
+					fmt.Sprintf("The value of the label %q of %s %s.", key, r, problem),
+					"metadata", field, key))
+			}
+		}
+	}
+	return annotations
+}
+
+// checkDeprecatedAnnotations warns when a resource or its pod template uses an
+// annotation that has been deprecated, suggesting its replacement.
+func checkDeprecatedAnnotations(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().DeprecatedAnnotations
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	metadataPaths := [][]interface{}{{"metadata"}}
+	if template, ok := r.podTemplatePath(); ok && len(template) > 0 {
+		metadataPaths = append(metadataPaths, joinPath(template, "metadata"))
+	}
+
+	var annotations Annotations
+	for _, metadata := range metadataPaths {
+		for _, key := range sortedKeys(stringMap(r.get(joinPath(metadata, "annotations")...))) {
+			replacement, ok := policy.Annotations[key]
+			if !ok {
+				continue
+			}
+			message := fmt.Sprintf("The %s annotation of %s is deprecated.", key, r)
+			if replacement != "" {
+				message = fmt.Sprintf("%s %s", message, replacement)
+			}
+			annotations = append(annotations, r.annotation(level, deprecatedAnnotationTitle, message,
+				joinPath(metadata, "annotations", key)...))
+		}
+	}
+	return annotations
+}
+
+// checkRecommendedLabels warns when a workload is missing any of the
+// app.kubernetes.io/ labels Kubernetes recommends, or the configured subset of
+// them.
+func checkRecommendedLabels(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().RecommendedLabels
+	if policy == nil {
+		return nil
+	}
+	if _, ok := r.podTemplatePath(); !ok {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	required := policy.Labels
+	if len(required) == 0 {
+		required = recommendedLabels
+	}
+	labels := stringMap(r.get("metadata", "labels"))
+	var missing []string
+	for _, label := range required {
+		key := "app.kubernetes.io/" + label
+		if _, ok := labels[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return Annotations{r.annotation(level, recommendedLabelsTitle,
+		fmt.Sprintf("%s is missing the recommended %s labels.", r, strings.Join(missing, ", ")),
+		"metadata", "labels")}
+}
+
+// checkExportedFields warns when a resource contains fields managed by the API
+// server, such as metadata.uid or status, which shouldn't be committed.
+func checkExportedFields(r *Resource, resources []*Resource) Annotations {
+	rule := r.policies().ExportedFields
+	if rule == nil {
+		return nil
+	}
+	level := rule.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, path := range exportedFields {
+		if r.get(path...) == nil {
+			continue
+		}
+		var elements []string
+		for _, element := range path {
+			elements = append(elements, fmt.Sprintf("%v", element))
+		}
+		field := strings.Join(elements, ".")
+		annotations = append(annotations, r.annotation(level, exportedFieldTitle,
+			fmt.Sprintf("%s sets %s, which is managed by the API server. Remove it, along with any other fields exported from the cluster.", r, field),
+			path...))
+	}
+	return annotations
+}
@@ -0,0 +1,111 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestMetadataKeys(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/metadata/keys.yaml")
+
+	path := github.String("fixtures/checks/metadata/keys.yaml")
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(10), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(7), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(8), AnnotationLevel: github.String("failure")},
+	)
+	if want := "has a name of 69 characters, longer than the maximum of 63"; len(annotations) == 3 && !strings.Contains(annotations[1].GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotations[1].GetMessage())
+	}
+}
+
+func TestMetadataKeysCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		MetadataKeys: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/metadata/keys.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestDeprecatedAnnotations(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		DeprecatedAnnotations: &KubeValidatorConfigDeprecatedAnnotations{
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class":              "Set spec.ingressClassName instead.",
+				"seccomp.security.alpha.kubernetes.io/pod": "Set securityContext.seccompProfile instead.",
+			},
+		},
+	}, "fixtures/checks/metadata/deprecated.yaml")
+
+	path := github.String("fixtures/checks/metadata/deprecated.yaml")
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(34), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(6), AnnotationLevel: github.String("warning")},
+	)
+	if want := "Set spec.ingressClassName instead."; len(annotations) == 2 && !strings.Contains(annotations[1].GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotations[1].GetMessage())
+	}
+}
+
+func TestDeprecatedAnnotationsIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/metadata/deprecated.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestRecommendedLabels(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RecommendedLabels: &KubeValidatorConfigRecommendedLabels{Labels: []string{"name", "part-of"}},
+	}, "fixtures/checks/metadata/recommended.yaml")
+
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/metadata/recommended.yaml"),
+		StartLine:       github.Int(5),
+		AnnotationLevel: github.String("warning"),
+	})
+	if want := "app.kubernetes.io/name"; len(annotations) == 1 && !strings.Contains(annotations[0].GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotations[0].GetMessage())
+	}
+}
+
+func TestRecommendedLabelsDefaultToAll(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RecommendedLabels: &KubeValidatorConfigRecommendedLabels{},
+	}, "fixtures/checks/metadata/recommended.yaml")
+
+	path := github.String("fixtures/checks/metadata/recommended.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(25), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(5), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestRecommendedLabelsIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/metadata/recommended.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestExportedFields(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ExportedFields: &KubeValidatorConfigRule{},
+	}, "fixtures/checks/metadata/exported.yaml")
+
+	path := github.String("fixtures/checks/metadata/exported.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(11), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(24), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(6), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestExportedFieldsIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/metadata/exported.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
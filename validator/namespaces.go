@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const (
+	disallowedNamespaceTitle = "Namespace not allowed"
+	missingNamespaceTitle    = "Namespace not set"
+	namespaceLayoutTitle     = "Namespace doesn't match path"
+)
+
+// checkAllowedNamespaces fails when a namespaced resource targets a namespace
+// which doesn't match any of the allowed globs. Resources without a namespace
+// are checked against the configured default namespace, if any.
+func checkAllowedNamespaces(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().AllowedNamespaces
+	if policy == nil || !r.namespaced() {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	namespace := r.Namespace()
+	if namespace == "" {
+		namespace = policy.DefaultNamespace
+	}
+	if namespace == "" {
+		return nil
+	}
+
+	for _, glob := range policy.Namespaces {
+		if matched, _ := doublestar.Match(glob, namespace); matched {
+			return nil
+		}
+	}
+
+	return Annotations{r.annotation(level, disallowedNamespaceTitle,
+		fmt.Sprintf("%s targets the %s namespace, which isn't one of the allowed namespaces: %s", r, namespace, strings.Join(policy.Namespaces, ", ")),
+		"metadata", "namespace")}
+}
+
+// checkExplicitNamespace fails when a namespaced resource doesn't set its
+// namespace, as it would be created in whichever namespace the client applying
+// it happens to use.
+func checkExplicitNamespace(r *Resource, resources []*Resource) Annotations {
+	rule := r.policies().RequireExplicitNamespace
+	if rule == nil || !r.namespaced() || r.Namespace() != "" {
+		return nil
+	}
+	level := rule.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	return Annotations{r.annotation(level, missingNamespaceTitle,
+		fmt.Sprintf("%s doesn't set metadata.namespace, so it would be created in the namespace of whichever client applies it.", r),
+		"metadata")}
+}
+
+// checkNamespaceLayout fails when the namespace of a resource differs from the
+// one implied by the path of its file. Resources without a namespace are
+// skipped, as tools applying a directory often set the namespace of its
+// resources.
+func checkNamespaceLayout(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().NamespaceLayout
+	if policy == nil || !r.namespaced() || r.Namespace() == "" {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	path := r.candidate.file.GetFilename()
+	expected, ok := policy.expectedNamespace(path)
+	if !ok || expected == r.Namespace() {
+		return nil
+	}
+	return Annotations{r.annotation(level, namespaceLayoutTitle,
+		fmt.Sprintf("%s targets the %s namespace, but %s is in the directory of the %s namespace.", r, r.Namespace(), path, expected),
+		"metadata", "namespace")}
+}
+
+// expectedNamespace returns the namespace captured by the first of the
+// layout's patterns matching path
+func (policy *KubeValidatorConfigNamespaceLayout) expectedNamespace(path string) (string, bool) {
+	for _, pattern := range policy.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		if match := re.FindStringSubmatch(path); len(match) > 1 {
+			return match[1], true
+		}
+	}
+	return "", false
+}
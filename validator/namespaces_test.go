@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAllowedNamespaces(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		AllowedNamespaces: &KubeValidatorConfigAllowedNamespaces{
+			Namespaces: []string{"team-a-*"},
+		},
+	}, "fixtures/checks/namespaces/allowed.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/allowed.yaml"),
+		StartLine:       github.Int(11),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestAllowedNamespacesWithDefaultNamespace(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		AllowedNamespaces: &KubeValidatorConfigAllowedNamespaces{
+			Namespaces:       []string{"team-a-*"},
+			DefaultNamespace: "default",
+		},
+	}, "fixtures/checks/namespaces/allowed.yaml")
+
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/namespaces/allowed.yaml"),
+			StartLine:       github.Int(11),
+			AnnotationLevel: github.String("failure"),
+		},
+		&github.CheckRunAnnotation{
+			Path:            github.String("fixtures/checks/namespaces/allowed.yaml"),
+			StartLine:       github.Int(15),
+			AnnotationLevel: github.String("failure"),
+		},
+	)
+}
+
+func TestRequireExplicitNamespace(t *testing.T) {
+	policies := &KubeValidatorConfigPolicies{}
+	if err := yaml.Unmarshal([]byte("requireExplicitNamespace: true\nallowedNamespaces:\n  level: warning\n  namespaces: [web]\n"), policies); err != nil {
+		t.Fatal(err)
+	}
+	if policies.AllowedNamespaces.Level != levelWarning {
+		t.Errorf("expected the level of rules with options to be parsed, got %q", policies.AllowedNamespaces.Level)
+	}
+	candidates := fixtureCandidates(t, policies, "fixtures/checks/namespaces/explicit.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/explicit.yaml"),
+		StartLine:       github.Int(3),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestRequireExplicitNamespaceCanBeDisabledWithFalse(t *testing.T) {
+	policies := &KubeValidatorConfigPolicies{}
+	if err := yaml.Unmarshal([]byte("requireExplicitNamespace: false\n"), policies); err != nil {
+		t.Fatal(err)
+	}
+	candidates := fixtureCandidates(t, policies, "fixtures/checks/namespaces/explicit.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestNamespaceLayout(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NamespaceLayout: &KubeValidatorConfigNamespaceLayout{
+			Patterns: []string{`clusters/[^/]+/namespaces/([^/]+)/`},
+		},
+	}, "fixtures/checks/namespaces/clusters/prod/namespaces/payments/resources.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/namespaces/clusters/prod/namespaces/payments/resources.yaml"),
+		StartLine:       github.Int(13),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestNamespaceLayoutIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/namespaces/clusters/prod/namespaces/payments/resources.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestNamespaceLayoutPatternsNeedACaptureGroup(t *testing.T) {
+	for pattern, want := range map[string]bool{
+		`namespaces/([^/]+)/`: true,
+		`namespaces/[^/]+/`:   false,
+		`namespaces/(`:        false,
+	} {
+		policies := &KubeValidatorConfigPolicies{
+			NamespaceLayout: &KubeValidatorConfigNamespaceLayout{Patterns: []string{pattern}},
+		}
+		if got := policies.valid(); got != want {
+			t.Errorf("expected %s to be valid: %v, got %v", pattern, want, got)
+		}
+	}
+}
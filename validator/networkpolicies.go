@@ -0,0 +1,49 @@
+package validator
+
+import "fmt"
+
+const ignoredNetworkPolicyRulesTitle = "Ignored NetworkPolicy rules"
+
+// networkPolicyDirections maps the rule blocks of a NetworkPolicy to the
+// policyTypes which enable them
+var networkPolicyDirections = []struct {
+	field      string
+	policyType string
+}{
+	{"ingress", "Ingress"},
+	{"egress", "Egress"},
+}
+
+// checkNetworkPolicyTypes warns when a NetworkPolicy sets policyTypes without
+// the direction of one of its ingress or egress rule blocks, which Kubernetes
+// then ignores. Listing a direction without rules denies all traffic in that
+// direction, which is intentional often enough not to be annotated.
+func checkNetworkPolicyTypes(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "NetworkPolicy" {
+		return nil
+	}
+	level := r.policies().NetworkPolicyTypes.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+	list, ok := r.get("spec", "policyTypes").([]interface{})
+	if !ok {
+		// Without policyTypes, the directions are inferred from the rules
+		return nil
+	}
+	var policyTypes []string
+	for _, v := range list {
+		policyTypes = append(policyTypes, fmt.Sprintf("%v", v))
+	}
+
+	var annotations Annotations
+	for _, direction := range networkPolicyDirections {
+		if r.get("spec", direction.field) == nil || containsString(policyTypes, direction.policyType) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, ignoredNetworkPolicyRulesTitle,
+			fmt.Sprintf("%s defines %s rules, but its policyTypes don't include %s, so they're ignored. Add %s to policyTypes or remove the rules.", r, direction.field, direction.policyType, direction.policyType),
+			"spec", direction.field))
+	}
+	return annotations
+}
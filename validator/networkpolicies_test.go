@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestNetworkPolicyTypes(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/networkpolicies/policy-types.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/networkpolicies/policy-types.yaml"),
+		StartLine:       github.Int(17),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestNetworkPolicyTypesCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NetworkPolicyTypes: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/networkpolicies/policy-types.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
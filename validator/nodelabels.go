@@ -0,0 +1,180 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const unknownNodeLabelTitle = "Unknown node label"
+
+// wellKnownNodeLabels contains globs matching the labels set on nodes by
+// Kubernetes and common cloud providers
+var wellKnownNodeLabels = []string{
+	"kubernetes.io/hostname",
+	"kubernetes.io/os",
+	"kubernetes.io/arch",
+	"topology.kubernetes.io/region",
+	"topology.kubernetes.io/zone",
+	"node.kubernetes.io/instance-type",
+	"node.kubernetes.io/windows-build",
+	"node-role.kubernetes.io/*",
+	"beta.kubernetes.io/os",
+	"beta.kubernetes.io/arch",
+	"beta.kubernetes.io/instance-type",
+	"failure-domain.beta.kubernetes.io/region",
+	"failure-domain.beta.kubernetes.io/zone",
+	"cloud.google.com/*",
+	"eks.amazonaws.com/*",
+	"karpenter.sh/*",
+	"kubernetes.azure.com/*",
+}
+
+// nodeLabelKey is a node label key referenced by a pod spec
+type nodeLabelKey struct {
+	key  string
+	path []interface{}
+}
+
+// nodeLabelKeys returns the node label keys referenced by the nodeSelector,
+// node affinity and topology keys of the pod spec at spec
+func (r *Resource) nodeLabelKeys(spec []interface{}) []nodeLabelKey {
+	var keys []nodeLabelKey
+	for _, key := range sortedKeys(stringMap(r.get(joinPath(spec, "nodeSelector")...))) {
+		keys = append(keys, nodeLabelKey{key: key, path: joinPath(spec, "nodeSelector", key)})
+	}
+
+	nodeAffinity := joinPath(spec, "affinity", "nodeAffinity")
+	required := joinPath(nodeAffinity, "requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+	terms, _ := r.get(required...).([]interface{})
+	for i := range terms {
+		keys = append(keys, r.matchExpressionKeys(joinPath(required, i, "matchExpressions"))...)
+	}
+	preferred := joinPath(nodeAffinity, "preferredDuringSchedulingIgnoredDuringExecution")
+	terms, _ = r.get(preferred...).([]interface{})
+	for i := range terms {
+		keys = append(keys, r.matchExpressionKeys(joinPath(preferred, i, "preference", "matchExpressions"))...)
+	}
+
+	for _, affinity := range []string{"podAffinity", "podAntiAffinity"} {
+		required := joinPath(spec, "affinity", affinity, "requiredDuringSchedulingIgnoredDuringExecution")
+		terms, _ := r.get(required...).([]interface{})
+		for i := range terms {
+			keys = append(keys, r.topologyKey(joinPath(required, i, "topologyKey"))...)
+		}
+		preferred := joinPath(spec, "affinity", affinity, "preferredDuringSchedulingIgnoredDuringExecution")
+		terms, _ = r.get(preferred...).([]interface{})
+		for i := range terms {
+			keys = append(keys, r.topologyKey(joinPath(preferred, i, "podAffinityTerm", "topologyKey"))...)
+		}
+	}
+
+	constraints, _ := r.get(joinPath(spec, "topologySpreadConstraints")...).([]interface{})
+	for i := range constraints {
+		keys = append(keys, r.topologyKey(joinPath(spec, "topologySpreadConstraints", i, "topologyKey"))...)
+	}
+	return keys
+}
+
+func (r *Resource) matchExpressionKeys(path []interface{}) []nodeLabelKey {
+	var keys []nodeLabelKey
+	expressions, _ := r.get(path...).([]interface{})
+	for i := range expressions {
+		if key := r.getString(joinPath(path, i, "key")...); key != "" {
+			keys = append(keys, nodeLabelKey{key: key, path: joinPath(path, i, "key")})
+		}
+	}
+	return keys
+}
+
+func (r *Resource) topologyKey(path []interface{}) []nodeLabelKey {
+	if key := r.getString(path...); key != "" {
+		return []nodeLabelKey{{key: key, path: path}}
+	}
+	return nil
+}
+
+// knownNodeLabel returns true when key matches a well known node label or one
+// of the allowed globs
+func knownNodeLabel(key string, allowed []string) bool {
+	for _, glob := range append(wellKnownNodeLabels, allowed...) {
+		if matched, _ := doublestar.Match(glob, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// similarNodeLabel returns the well known node label closest to key when it
+// looks like a typo of one
+func similarNodeLabel(key string) string {
+	best, bestDistance := "", 3
+	for _, label := range wellKnownNodeLabels {
+		if distance := editDistance(key, label); distance <= bestDistance {
+			best, bestDistance = label, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, i := range rest {
+		if i < first {
+			first = i
+		}
+	}
+	return first
+}
+
+// checkNodeLabels warns when a workload schedules pods using node labels that
+// are neither well known nor allowed, as a typo makes pods unschedulable.
+// Clusters often use custom node labels, so this check must be enabled
+// explicitly.
+func checkNodeLabels(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().NodeLabels
+	if policy == nil {
+		return nil
+	}
+	spec, ok := r.podSpecPath()
+	if !ok {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, key := range r.nodeLabelKeys(spec) {
+		if knownNodeLabel(key.key, policy.Allowed) {
+			continue
+		}
+		message := fmt.Sprintf("%s schedules pods using the node label %s, which isn't well known or allowed.", r, key.key)
+		if similar := similarNodeLabel(key.key); similar != "" {
+			message += fmt.Sprintf(" Did you mean %s?", similar)
+		}
+		annotations = append(annotations, r.annotation(level, unknownNodeLabelTitle, message, key.path...))
+	}
+	return annotations
+}
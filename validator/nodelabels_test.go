@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestNodeLabels(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NodeLabels: &KubeValidatorConfigNodeLabels{},
+	}, "fixtures/checks/nodelabels/typo.yaml")
+
+	path := github.String("fixtures/checks/nodelabels/typo.yaml")
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(16), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(20), AnnotationLevel: github.String("warning")},
+	)
+	if len(annotations) == 2 && !strings.Contains(annotations[1].GetMessage(), "Did you mean kubernetes.io/hostname?") {
+		t.Errorf("expected a suggestion for the typo, got %s", annotations[1].GetMessage())
+	}
+}
+
+func TestNodeLabelsAllowed(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NodeLabels: &KubeValidatorConfigNodeLabels{Allowed: []string{"example.com/*"}},
+	}, "fixtures/checks/nodelabels/typo.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/nodelabels/typo.yaml"),
+		StartLine:       github.Int(20),
+		AnnotationLevel: github.String("warning"),
+	})
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bradleyfalzon/ghinstallation"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// oneShotFormats write the check run concluded by a OneShot
+var oneShotFormats = map[string]func(io.Writer, *oneShotReport) error{
+	"text": writeOneShotText,
+	"json": writeOneShotJSON,
+}
+
+// OneShot processes a single webhook event payload and exits, for teams
+// running kubevalidator as a job rather than a server. Nothing is changed on
+// GitHub: the check run that would conclude the check suite is written to Out
+// in the configured Format instead.
+type OneShot struct {
+	EventType string
+	Payload   []byte
+	Format    string
+	Out       io.Writer
+
+	// Github is used to read the repository. When nil, a client is
+	// authenticated as the installation of the GitHub App in the payload.
+	Github         *github.Client
+	AppID          int
+	PrivateKeyFile string
+
+	// ImagePolicySources are globs matching the image policy files and URLs
+	// the repository's configuration may use
+	ImagePolicySources []string
+
+	// SchemaLocations are globs matching the schema locations the
+	// repository's configuration may use
+	SchemaLocations []string
+}
+
+// oneShotReport is the check run concluded by a OneShot
+type oneShotReport struct {
+	Conclusion  string      `json:"conclusion"`
+	Title       string      `json:"title"`
+	Summary     string      `json:"summary"`
+	Annotations Annotations `json:"annotations"`
+}
+
+// Run processes the payload and writes the check run it concluded to Out. It
+// returns the conclusion of the check run, which is empty when the event
+// didn't conclude one.
+func (o *OneShot) Run(ctx context.Context) (string, error) {
+	write, ok := oneShotFormats[o.Format]
+	if !ok {
+		return "", fmt.Errorf("Unknown format %s", o.Format)
+	}
+
+	event, err := github.ParseWebHook(o.EventType, o.Payload)
+	if err != nil {
+		return "", errors.Wrap(err, "Couldn't parse the event payload")
+	}
+	client := o.Github
+	if client == nil {
+		client, err = o.installationClient()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c := &Context{
+		Event:  event,
+		Ctx:    &ctx,
+		AppID:  &o.AppID,
+		Github: client,
+		DryRun: true,
+
+		ImagePolicySources: o.ImagePolicySources,
+		SchemaLocations:    o.SchemaLocations,
+	}
+	c.Process()
+	if c.panicked {
+		return "", errors.New("Couldn't process the event")
+	}
+	if len(c.checkRuns) == 0 {
+		return "", nil
+	}
+
+	// The last check run recorded is the one concluding the check suite
+	checkRun := c.checkRuns[len(c.checkRuns)-1]
+	report := &oneShotReport{
+		Conclusion:  checkRun.GetConclusion(),
+		Title:       checkRun.GetOutput().GetTitle(),
+		Summary:     checkRun.GetOutput().GetSummary(),
+		Annotations: checkRun.GetOutput().Annotations,
+	}
+	return report.Conclusion, write(o.Out, report)
+}
+
+// installationClient returns a client authenticated as the installation of
+// the GitHub App which received the payload
+func (o *OneShot) installationClient() (*github.Client, error) {
+	ge := &GenericEvent{}
+	if err := json.Unmarshal(o.Payload, ge); err != nil {
+		return nil, errors.Wrap(err, "Couldn't parse the event payload")
+	}
+	if ge.Installation == nil {
+		return nil, errors.New("The event payload doesn't identify an installation")
+	}
+	tr, err := ghinstallation.NewKeyFromFile(http.DefaultTransport, o.AppID, int(ge.Installation.GetID()), o.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewClient(&http.Client{Transport: tr}), nil
+}
+
+// writeOneShotText writes each annotation like the text format of the CLI,
+// followed by the conclusion and title of the check run
+func writeOneShotText(w io.Writer, report *oneShotReport) error {
+	if err := writeText(w, report.Annotations); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s: %s\n", report.Conclusion, report.Title)
+	return err
+}
+
+// writeOneShotJSON writes the conclusion, title, summary and annotations of
+// the check run as JSON
+func writeOneShotJSON(w io.Writer, report *oneShotReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const oneShotTestPayload = `{
+	"action": "requested",
+	"check_suite": {
+		"head_branch": "b",
+		"head_sha": "abc",
+		"pull_requests": [{"number": 1, "base": {"ref": "master"}}]
+	},
+	"repository": {"name": "r", "owner": {"login": "o"}}
+}`
+
+// serveContents responds to requests for path at the head of the check suite
+// with the contents of fixture
+func serveContents(t *testing.T, mux *http.ServeMux, path string, fixture string) {
+	b, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux.HandleFunc("/repos/o/r/contents/"+path, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString(b))
+	})
+}
+
+// oneShotTestServer returns a OneShot processing a check suite changing a
+// Helm chart, configured by the config fixture
+func oneShotTestServer(t *testing.T, config string) (*OneShot, *bytes.Buffer, func()) {
+	client, mux, _, teardown := setup()
+	serveContents(t, mux, ".github/kubevalidator.yaml", config)
+	serveContents(t, mux, "checks/helm/missing-version/Chart.yaml", "../fixtures/checks/helm/missing-version/Chart.yaml")
+	mux.HandleFunc("/repos/o/r/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"filename": "checks/helm/missing-version/Chart.yaml", "status": "added"}]`)
+	})
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no check runs to be created")
+	})
+
+	var out bytes.Buffer
+	return &OneShot{
+		EventType: "check_suite",
+		Payload:   []byte(oneShotTestPayload),
+		Github:    client,
+		Out:       &out,
+	}, &out, teardown
+}
+
+func TestOneShotText(t *testing.T) {
+	o, out, teardown := oneShotTestServer(t, "../fixtures/cli/kubevalidator.yaml")
+	defer teardown()
+	o.Format = "text"
+
+	conclusion, err := o.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conclusion != "failure" {
+		t.Errorf("expected a failure, got %q", conclusion)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 3 annotations and a conclusion, got\n%s", out.String())
+	}
+	if want := "checks/helm/missing-version/Chart.yaml:1: failure: Invalid Helm chart: version is required"; lines[0] != want {
+		t.Errorf("expected %q, got %q", want, lines[0])
+	}
+	if !strings.HasPrefix(lines[3], "failure: 1 file") {
+		t.Errorf("expected the conclusion and title, got %q", lines[3])
+	}
+}
+
+func TestOneShotJSON(t *testing.T) {
+	o, out, teardown := oneShotTestServer(t, "../fixtures/cli/kubevalidator.yaml")
+	defer teardown()
+	o.Format = "json"
+
+	if _, err := o.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var report oneShotReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Conclusion != "failure" || len(report.Annotations) != 3 {
+		t.Errorf("expected a failure with 3 annotations, got %s", out.String())
+	}
+	if !strings.Contains(report.Summary, "Chart.yaml") {
+		t.Errorf("expected the summary to list the file, got %q", report.Summary)
+	}
+}
+
+func TestOneShotUnknownFormat(t *testing.T) {
+	o := &OneShot{EventType: "check_suite", Payload: []byte(oneShotTestPayload), Format: "unknown"}
+	if _, err := o.Run(context.Background()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// outputFormats write annotations produced by the CLI
+var outputFormats = map[string]func(io.Writer, Annotations) error{
+	"text":           writeText,
+	"snapshot":       writeSnapshot,
+	"github-actions": writeGitHubActions,
+
+	"stats":              writeStats,
+	"stats-by-directory": writeStatsByDirectory,
+}
+
+// workflowCommands maps annotation levels to GitHub Actions workflow commands
+var workflowCommands = map[string]string{
+	levelNotice:  "notice",
+	levelWarning: "warning",
+	levelFailure: "error",
+}
+
+// writeText writes annotations in the file:line: form understood by editors
+func writeText(w io.Writer, annotations Annotations) error {
+	for _, a := range annotations {
+		_, err := fmt.Fprintf(w, "%s:%d: %s: %s: %s\n", a.GetPath(), a.GetStartLine(), a.GetAnnotationLevel(), a.GetTitle(), a.GetMessage())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSnapshot writes one tab separated line per annotation, sorted so that
+// the output is identical across runs and suitable for committing as a golden
+// file. Each line contains the file, line range, rule, level and message.
+func writeSnapshot(w io.Writer, annotations Annotations) error {
+	sorted := make(Annotations, len(annotations))
+	copy(sorted, annotations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.GetPath() != b.GetPath() {
+			return a.GetPath() < b.GetPath()
+		}
+		if a.GetStartLine() != b.GetStartLine() {
+			return a.GetStartLine() < b.GetStartLine()
+		}
+		if a.GetEndLine() != b.GetEndLine() {
+			return a.GetEndLine() < b.GetEndLine()
+		}
+		return snapshotLine(a) < snapshotLine(b)
+	})
+	for _, a := range sorted {
+		if _, err := fmt.Fprintln(w, snapshotLine(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func snapshotLine(a *github.CheckRunAnnotation) string {
+	return fmt.Sprintf("%s\t%d-%d\t%s\t%s\t%s", a.GetPath(), a.GetStartLine(), a.GetEndLine(), a.GetTitle(), a.GetAnnotationLevel(), escapeSnapshotField(a.GetMessage()))
+}
+
+func escapeSnapshotField(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\t", "\\t").Replace(s)
+}
+
+// writeGitHubActions writes annotations as workflow commands so that they're
+// displayed on the files changed by a Pull Request when run in GitHub Actions.
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+func writeGitHubActions(w io.Writer, annotations Annotations) error {
+	for _, a := range annotations {
+		command, ok := workflowCommands[a.GetAnnotationLevel()]
+		if !ok {
+			command = "error"
+		}
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,endLine=%d,title=%s::%s\n", command,
+			escapeWorkflowProperty(a.GetPath()), a.GetStartLine(), a.GetEndLine(), escapeWorkflowProperty(a.GetTitle()), escapeWorkflowData(a.GetMessage()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestSnapshotIsDeterministic(t *testing.T) {
+	annotations := Annotations{
+		{Path: github.String("b.yaml"), StartLine: github.Int(2), EndLine: github.Int(2), AnnotationLevel: github.String("warning"), Title: github.String("Rule"), Message: github.String("second\nline")},
+		{Path: github.String("a.yaml"), StartLine: github.Int(10), EndLine: github.Int(10), AnnotationLevel: github.String("failure"), Title: github.String("Rule"), Message: github.String("ten")},
+		{Path: github.String("a.yaml"), StartLine: github.Int(9), EndLine: github.Int(9), AnnotationLevel: github.String("failure"), Title: github.String("Rule"), Message: github.String("nine")},
+		{Path: github.String("a.yaml"), StartLine: github.Int(9), EndLine: github.Int(9), AnnotationLevel: github.String("failure"), Title: github.String("Another rule"), Message: github.String("nine")},
+	}
+	want := "a.yaml\t9-9\tAnother rule\tfailure\tnine\n" +
+		"a.yaml\t9-9\tRule\tfailure\tnine\n" +
+		"a.yaml\t10-10\tRule\tfailure\tten\n" +
+		"b.yaml\t2-2\tRule\twarning\tsecond\\nline\n"
+
+	for i := 0; i < len(annotations); i++ {
+		rotated := append(Annotations{}, annotations[i:]...)
+		rotated = append(rotated, annotations[:i]...)
+
+		var out bytes.Buffer
+		if err := writeSnapshot(&out, rotated); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != want {
+			t.Errorf("snapshot of rotation %d was\n%s\nwanted\n%s", i, out.String(), want)
+		}
+	}
+}
+
+func TestGitHubActionsWorkflowCommands(t *testing.T) {
+	annotations := Annotations{
+		{Path: github.String("config/deployment.yaml"), StartLine: github.Int(7), EndLine: github.Int(8), AnnotationLevel: github.String("failure"), Title: github.String("Error validating Deployment against master schema"), Message: github.String("spec.replicas: Invalid type.\n100% wrong")},
+		{Path: github.String("config/service.yaml"), StartLine: github.Int(3), EndLine: github.Int(3), AnnotationLevel: github.String("warning"), Title: github.String("Service has no matching workload"), Message: github.String("No workload")},
+	}
+	want := "::error file=config/deployment.yaml,line=7,endLine=8,title=Error validating Deployment against master schema::spec.replicas: Invalid type.%0A100%25 wrong\n" +
+		"::warning file=config/service.yaml,line=3,endLine=3,title=Service has no matching workload::No workload\n"
+
+	var out bytes.Buffer
+	if err := writeGitHubActions(&out, annotations); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("got\n%s\nwanted\n%s", out.String(), want)
+	}
+}
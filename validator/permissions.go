@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// requiredPermissions maps the permissions kubevalidator needs to the minimum
+// access it needs to them
+var requiredPermissions = map[string]string{
+	"checks":        "write",
+	"contents":      "read",
+	"pull_requests": "read",
+}
+
+// permissionAccess orders the levels of access a GitHub App can be granted
+var permissionAccess = map[string]int{
+	"read":  1,
+	"write": 2,
+	"admin": 3,
+}
+
+// appPermissions is the subset of the authenticated GitHub App containing its
+// permissions, which the version of go-github in use doesn't expose
+type appPermissions struct {
+	Permissions map[string]string `json:"permissions"`
+}
+
+// missingPermissions returns a description of each required permission the
+// authenticated GitHub App hasn't been granted
+func missingPermissions(ctx context.Context, client *github.Client) ([]string, error) {
+	req, err := client.NewRequest("GET", "app", nil)
+	if err != nil {
+		return nil, err
+	}
+	app := &appPermissions{}
+	if _, err := client.Do(ctx, req, app); err != nil {
+		return nil, errors.Wrap(err, "Couldn't get the permissions of the GitHub App")
+	}
+
+	var missing []string
+	for permission, access := range requiredPermissions {
+		granted := app.Permissions[permission]
+		if permissionAccess[granted] < permissionAccess[access] {
+			if granted == "" {
+				granted = "none"
+			}
+			missing = append(missing, fmt.Sprintf("%s: %s (granted %s)", permission, access, granted))
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
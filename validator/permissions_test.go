@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMissingPermissionsFailReadiness(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id": 1, "permissions": {"checks": "read", "contents": "write", "metadata": "read", "pull_requests": "read"}}`)
+	})
+
+	missing, err := missingPermissions(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "checks: write (granted read)" {
+		t.Errorf("expected checks write to be missing, got %v", missing)
+	}
+
+	s := &Server{GitHubAppClient: client, CheckPermissions: true}
+	s.checkPermissions(context.Background())
+	w := httptest.NewRecorder()
+	s.readiness(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the server not to be ready, got %d", w.Code)
+	}
+}
+
+func TestGrantedPermissionsPassReadiness(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "permissions": {"checks": "write", "contents": "read", "pull_requests": "read"}}`)
+	})
+
+	s := &Server{GitHubAppClient: client, CheckPermissions: true}
+	s.checkPermissions(context.Background())
+	w := httptest.NewRecorder()
+	s.readiness(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the server to be ready, got %d", w.Code)
+	}
+}
+
+func TestMissingPullRequestsPermission(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "permissions": {"checks": "write", "contents": "read"}}`)
+	})
+
+	missing, err := missingPermissions(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "pull_requests: read (granted none)" {
+		t.Errorf("expected pull requests read to be missing, got %v", missing)
+	}
+}
+
+func TestReadinessChecksPermissionsAgain(t *testing.T) {
+	defer func() { now = time.Now }()
+	start := time.Now()
+	now = func() time.Time { return start }
+
+	client, mux, _, teardown := setup()
+	defer teardown()
+	checks := "read"
+	requests := 0
+	mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"id": 1, "permissions": {"checks": %q, "contents": "read", "pull_requests": "read"}}`, checks)
+	})
+
+	s := &Server{GitHubAppClient: client, CheckPermissions: true}
+	s.checkPermissions(context.Background())
+	checks = "write"
+	w := httptest.NewRecorder()
+	s.readiness(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || requests != 1 {
+		t.Errorf("expected the permissions not to be checked again within a minute, got %d after %d requests", w.Code, requests)
+	}
+
+	now = func() time.Time { return start.Add(permissionsRecheckInterval) }
+	w = httptest.NewRecorder()
+	s.readiness(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusOK || requests != 2 {
+		t.Errorf("expected the server to be ready once permissions are granted, got %d after %d requests", w.Code, requests)
+	}
+}
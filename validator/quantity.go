@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// quantityPattern matches Kubernetes resource quantities such as 500m, 10Gi
+// or 1e3
+var quantityPattern = regexp.MustCompile(`^([+-]?[0-9]*\.?[0-9]+)(Ki|Mi|Gi|Ti|Pi|Ei|n|u|m|k|M|G|T|P|E|[eE][+-]?[0-9]+)?$`)
+
+// quantitySuffixes maps the suffixes of quantities to their multipliers
+var quantitySuffixes = map[string]*big.Rat{
+	"":   big.NewRat(1, 1),
+	"n":  big.NewRat(1, 1000000000),
+	"u":  big.NewRat(1, 1000000),
+	"m":  big.NewRat(1, 1000),
+	"k":  big.NewRat(1000, 1),
+	"M":  new(big.Rat).SetFloat64(1e6),
+	"G":  new(big.Rat).SetFloat64(1e9),
+	"T":  new(big.Rat).SetFloat64(1e12),
+	"P":  new(big.Rat).SetFloat64(1e15),
+	"E":  new(big.Rat).SetFloat64(1e18),
+	"Ki": new(big.Rat).SetInt64(1 << 10),
+	"Mi": new(big.Rat).SetInt64(1 << 20),
+	"Gi": new(big.Rat).SetInt64(1 << 30),
+	"Ti": new(big.Rat).SetInt64(1 << 40),
+	"Pi": new(big.Rat).SetInt64(1 << 50),
+	"Ei": new(big.Rat).SetInt64(1 << 60),
+}
+
+// parseQuantity parses a Kubernetes resource quantity. Numbers are accepted
+// too, as unquoted quantities are parsed as such from YAML.
+func parseQuantity(v interface{}) (*big.Rat, error) {
+	s := fmt.Sprintf("%v", v)
+	match := quantityPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("%s isn't a valid quantity", s)
+	}
+	number, ok := new(big.Rat).SetString(match[1])
+	if !ok {
+		return nil, fmt.Errorf("%s isn't a valid quantity", s)
+	}
+	multiplier, ok := quantitySuffixes[match[2]]
+	if !ok {
+		// An exponent such as e3
+		multiplier, ok = new(big.Rat).SetString("1" + match[2])
+		if !ok {
+			return nil, fmt.Errorf("%s isn't a valid quantity", s)
+		}
+	}
+	return number.Mul(number, multiplier), nil
+}
@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	valid := map[interface{}]*big.Rat{
+		"100Gi": new(big.Rat).SetInt64(100 << 30),
+		"1.5G":  new(big.Rat).SetInt64(1500000000),
+		"500m":  big.NewRat(1, 2),
+		"1e3":   new(big.Rat).SetInt64(1000),
+		2:       new(big.Rat).SetInt64(2),
+		"0.5":   big.NewRat(1, 2),
+	}
+	for quantity, want := range valid {
+		got, err := parseQuantity(quantity)
+		if err != nil {
+			t.Errorf("%v: unexpected error %s", quantity, err)
+			continue
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("%v: got %s, wanted %s", quantity, got, want)
+		}
+	}
+
+	for _, quantity := range []string{"", "Gi", "10GB", "1.2.3", "lots"} {
+		if _, err := parseQuantity(quantity); err == nil {
+			t.Errorf("%s: expected an error", quantity)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const rbacWildcardTitle = "Wildcard RBAC rule"
+
+// checkRBACWildcards warns when a rule of a Role or ClusterRole grants every
+// verb, resource or API group with *, unless wildcards are allowed for that
+// field.
+func checkRBACWildcards(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().RBACWildcards
+	if policy == nil || (r.Kind() != "Role" && r.Kind() != "ClusterRole") {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	rules, _ := r.get("rules").([]interface{})
+	for i := range rules {
+		var wildcards []string
+		for _, field := range []struct {
+			name    string
+			allowed bool
+		}{
+			{"apiGroups", policy.AllowAPIGroups},
+			{"resources", policy.AllowResources},
+			{"verbs", policy.AllowVerbs},
+		} {
+			values, _ := r.get("rules", i, field.name).([]interface{})
+			for _, value := range values {
+				if value == "*" && !field.allowed {
+					wildcards = append(wildcards, field.name)
+					break
+				}
+			}
+		}
+		if len(wildcards) == 0 {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, rbacWildcardTitle,
+			fmt.Sprintf("Rule %d of %s grants every one of its %s with *. List what's needed instead.", i, r, strings.Join(wildcards, ", ")),
+			"rules", i))
+	}
+	return annotations
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestRBACWildcards(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RBACWildcards: &KubeValidatorConfigRBACWildcards{},
+	}, "fixtures/checks/rbac/wildcards.yaml")
+
+	path := github.String("fixtures/checks/rbac/wildcards.yaml")
+	annotations := candidates.Check()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(19), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(6), AnnotationLevel: github.String("warning")},
+	)
+	if want := "apiGroups, resources, verbs"; len(annotations) == 2 && !strings.Contains(annotations[1].GetMessage(), want) {
+		t.Errorf("expected %q in %s", want, annotations[1].GetMessage())
+	}
+}
+
+func TestRBACWildcardsCanBeAllowed(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RBACWildcards: &KubeValidatorConfigRBACWildcards{AllowVerbs: true},
+	}, "fixtures/checks/rbac/wildcards.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/rbac/wildcards.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestRBACWildcardsIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/rbac/wildcards.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
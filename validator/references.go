@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const (
+	unresolvedVolumeReferenceTitle = "Volume references a missing resource"
+	optionalReferenceTitle         = "Mismatched optional reference"
+)
+
+// reference is a reference from a pod spec to a ConfigMap or Secret
+type reference struct {
+	kind     string
+	name     string
+	optional bool
+	path     []interface{}
+}
+
+// volumeReferences returns the ConfigMaps and Secrets mounted as volumes by a
+// workload, including those projected into a volume
+func (r *Resource) volumeReferences() []reference {
+	spec, ok := r.podSpecPath()
+	if !ok {
+		return nil
+	}
+
+	var references []reference
+	volumes, _ := r.get(joinPath(spec, "volumes")...).([]interface{})
+	for i := range volumes {
+		volume := joinPath(spec, "volumes", i)
+		references = append(references, r.referenceAt(joinPath(volume, "configMap"), "ConfigMap", "name")...)
+		references = append(references, r.referenceAt(joinPath(volume, "secret"), "Secret", "secretName")...)
+
+		sources, _ := r.get(joinPath(volume, "projected", "sources")...).([]interface{})
+		for j := range sources {
+			source := joinPath(volume, "projected", "sources", j)
+			references = append(references, r.referenceAt(joinPath(source, "configMap"), "ConfigMap", "name")...)
+			references = append(references, r.referenceAt(joinPath(source, "secret"), "Secret", "name")...)
+		}
+	}
+	return references
+}
+
+// envReferences returns the ConfigMaps and Secrets referenced by the envFrom
+// and valueFrom of a workload's containers
+func (r *Resource) envReferences() []reference {
+	var references []reference
+	for _, c := range r.containers() {
+		sources, _ := c.get("envFrom").([]interface{})
+		for i := range sources {
+			source := joinPath(c.path, "envFrom", i)
+			references = append(references, r.referenceAt(joinPath(source, "configMapRef"), "ConfigMap", "name")...)
+			references = append(references, r.referenceAt(joinPath(source, "secretRef"), "Secret", "name")...)
+		}
+		env, _ := c.get("env").([]interface{})
+		for i := range env {
+			valueFrom := joinPath(c.path, "env", i, "valueFrom")
+			references = append(references, r.referenceAt(joinPath(valueFrom, "configMapKeyRef"), "ConfigMap", "name")...)
+			references = append(references, r.referenceAt(joinPath(valueFrom, "secretKeyRef"), "Secret", "name")...)
+		}
+	}
+	return references
+}
+
+// referenceAt returns the reference to a resource of kind whose name is found
+// in the nameField of the mapping at path
+func (r *Resource) referenceAt(path []interface{}, kind string, nameField string) []reference {
+	name, _ := r.get(joinPath(path, nameField)...).(string)
+	if name == "" {
+		return nil
+	}
+	optional, _ := r.get(joinPath(path, "optional")...).(bool)
+	return []reference{{
+		kind:     kind,
+		name:     name,
+		optional: optional,
+		path:     joinPath(path, nameField),
+	}}
+}
+
+// resolves returns true when a resource of the referenced kind and name is
+// defined in namespace
+func (ref reference) resolves(namespace string, resources []*Resource) bool {
+	for _, r := range resources {
+		if r.Kind() == ref.kind && r.Name() == ref.name && r.Namespace() == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns true when the referenced resource matches one of the globs
+// of resources known to exist outside of the Pull Request
+func (ref reference) allowed(configMaps []string, secrets []string) bool {
+	globs := configMaps
+	if ref.kind == "Secret" {
+		globs = secrets
+	}
+	for _, glob := range globs {
+		if matched, _ := doublestar.Match(glob, ref.name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVolumeReferences fails when a volume references a ConfigMap or Secret
+// that isn't defined in the Pull Request or allowed explicitly. Optional
+// references only produce warnings.
+func checkVolumeReferences(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().VolumeReferences
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, ref := range r.volumeReferences() {
+		if ref.resolves(r.Namespace(), resources) || ref.allowed(policy.ConfigMaps, policy.Secrets) {
+			continue
+		}
+		referenceLevel := level
+		if ref.optional && level == levelFailure {
+			referenceLevel = levelWarning
+		}
+		annotations = append(annotations, r.annotation(referenceLevel, unresolvedVolumeReferenceTitle,
+			fmt.Sprintf("%s mounts the %s %s, which isn't defined in this Pull Request.", r, ref.kind, ref.name),
+			ref.path...))
+	}
+	return annotations
+}
+
+// checkOptionalReferences warns when the envFrom or valueFrom of a container
+// marks a reference to a ConfigMap or Secret defined in the Pull Request as
+// optional, as it's likely required, or when a required reference is neither
+// defined in the Pull Request nor allowed explicitly.
+func checkOptionalReferences(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().OptionalReferences
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, ref := range r.envReferences() {
+		resolves := ref.resolves(r.Namespace(), resources)
+		switch {
+		case resolves && ref.optional:
+			annotations = append(annotations, r.annotation(level, optionalReferenceTitle,
+				fmt.Sprintf("%s marks its reference to the %s %s as optional, but it's defined in this Pull Request. Remove optional if it's required.", r, ref.kind, ref.name),
+				ref.path...))
+		case !resolves && !ref.optional && !ref.allowed(policy.ConfigMaps, policy.Secrets):
+			annotations = append(annotations, r.annotation(level, optionalReferenceTitle,
+				fmt.Sprintf("%s requires the %s %s, which isn't defined in this Pull Request. Mark the reference optional, or allow it if it exists in the cluster.", r, ref.kind, ref.name),
+				ref.path...))
+		}
+	}
+	return annotations
+}
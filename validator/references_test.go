@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestVolumeReferences(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		VolumeReferences: &KubeValidatorConfigReferences{
+			Secrets: []string{"*-tls"},
+		},
+	}, "fixtures/checks/references/volumes.yaml")
+
+	path := github.String("fixtures/checks/references/volumes.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(30), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(33), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(42), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestOptionalReferences(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		OptionalReferences: &KubeValidatorConfigReferences{
+			Secrets: []string{"registry-*"},
+		},
+	}, "fixtures/checks/references/optional.yaml")
+
+	path := github.String("fixtures/checks/references/optional.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(26), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(34), AnnotationLevel: github.String("warning")},
+	)
+}
+
+func TestOptionalReferencesIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/references/optional.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const zeroReplicasTitle = "Zero replicas"
+
+// inlineComment returns the comment ending line n of the Candidate's file, if
+// any
+func (c *Candidate) inlineComment(n int) string {
+	if c.bytes == nil {
+		return ""
+	}
+	lines := bytes.Split(*c.bytes, []byte("\n"))
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	text := string(bytes.TrimRight(lines[n-1], "\r"))
+	i := strings.Index(text, " #")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(text[i+2:])
+}
+
+// checkZeroReplicas warns when a workload sets replicas to 0, which disables
+// it and is often left over from testing. Workloads scaled down on purpose
+// can be allowed in the configuration, or by explaining why with a comment on
+// the same line as replicas.
+func checkZeroReplicas(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().ZeroReplicas
+	if policy == nil || !scalableKinds[r.Kind()] {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+	if replicas, ok := intValue(r.get("spec", "replicas")); !ok || replicas != 0 {
+		return nil
+	}
+	if policy.allowed(r.Namespace(), r.Name()) || r.candidate.inlineComment(r.line("spec", "replicas")) != "" {
+		return nil
+	}
+
+	return Annotations{r.annotation(level, zeroReplicasTitle,
+		fmt.Sprintf("%s sets replicas to 0, so none of its pods will run. If that's intended, explain why in a comment on this line.", r),
+		"spec", "replicas")}
+}
+
+// allowed returns true when namespace/name matches one of the globs of
+// workloads which may be scaled to zero
+func (policy *KubeValidatorConfigZeroReplicas) allowed(namespace, name string) bool {
+	for _, glob := range policy.Workloads {
+		if matched, _ := doublestar.Match(glob, fmt.Sprintf("%s/%s", namespace, name)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestZeroReplicas(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ZeroReplicas: &KubeValidatorConfigZeroReplicas{
+			Workloads: []string{"staging/*"},
+		},
+	}, "fixtures/checks/replicas/zero.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/replicas/zero.yaml"),
+		StartLine:       github.Int(7),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestZeroReplicasIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/replicas/zero.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
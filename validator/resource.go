@@ -0,0 +1,267 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/google/go-github/github"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Resource represents a single Kubernetes object parsed from a Candidate
+type Resource struct {
+	candidate *Candidate
+	object    map[string]interface{}
+	bytes     []byte
+	offset    int
+	tokens    []lineToken
+}
+
+var documentSeparator = regexp.MustCompile(`^---(\s.*)?$`)
+
+// document is a single YAML document found in a file. offset is the number of
+// lines in the file preceding it.
+type document struct {
+	bytes  []byte
+	offset int
+}
+
+// splitDocuments splits b on YAML document separators
+func splitDocuments(b []byte) []document {
+	var documents []document
+	lines := bytes.Split(b, []byte("\n"))
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !documentSeparator.Match(bytes.TrimRight(lines[i], "\r")) {
+			continue
+		}
+		documents = append(documents, document{
+			bytes:  bytes.Join(lines[start:i], []byte("\n")),
+			offset: start,
+		})
+		start = i + 1
+	}
+	return documents
+}
+
+// parseResources splits b into YAML documents and returns a Resource for each
+// document containing a Kubernetes resource. Documents that can't be parsed or
+// are malformed are skipped as they're annotated when validated.
+func parseResources(c *Candidate, b []byte) []*Resource {
+	var resources []*Resource
+	for _, d := range splitDocuments(b) {
+		if c != nil && c.malformedDocumentAnnotation(d) != nil {
+			continue
+		}
+		if r := parseResource(c, d.bytes, d.offset); r != nil && r.isKubernetesResource() {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+func parseResource(c *Candidate, document []byte, offset int) *Resource {
+	var body interface{}
+	if err := yaml.Unmarshal(document, &body); err != nil {
+		return nil
+	}
+	object, ok := convertToStringKeys(body).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return &Resource{
+		candidate: c,
+		object:    object,
+		bytes:     document,
+		offset:    offset,
+	}
+}
+
+// convertToStringKeys converts the map[interface{}]interface{} values produced
+// by yaml.Unmarshal into map[string]interface{} values.
+func convertToStringKeys(i interface{}) interface{} {
+	switch x := i.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range x {
+			m[fmt.Sprintf("%v", k)] = convertToStringKeys(v)
+		}
+		return m
+	case []interface{}:
+		for i, v := range x {
+			x[i] = convertToStringKeys(v)
+		}
+	}
+	return i
+}
+
+// Kind returns the kind of the Resource
+func (r *Resource) Kind() string {
+	return r.getString("kind")
+}
+
+// APIVersion returns the apiVersion of the Resource
+func (r *Resource) APIVersion() string {
+	return r.getString("apiVersion")
+}
+
+// Name returns the name of the Resource
+func (r *Resource) Name() string {
+	return r.getString("metadata", "name")
+}
+
+// Namespace returns the namespace of the Resource
+func (r *Resource) Namespace() string {
+	return r.getString("metadata", "namespace")
+}
+
+// isKubernetesResource returns false for mappings lacking an apiVersion or kind
+func (r *Resource) isKubernetesResource() bool {
+	return r.APIVersion() != "" && r.Kind() != ""
+}
+
+func (r *Resource) String() string {
+	return fmt.Sprintf("%s %s", r.Kind(), r.Name())
+}
+
+func (r *Resource) get(path ...interface{}) interface{} {
+	return valueAt(r.object, path)
+}
+
+func (r *Resource) getString(path ...interface{}) string {
+	s, _ := r.get(path...).(string)
+	return s
+}
+
+// valueAt walks v along path, returning nil if any element is missing
+func valueAt(v interface{}, path []interface{}) interface{} {
+	for _, element := range path {
+		switch e := element.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[e]
+		case int:
+			s, ok := v.([]interface{})
+			if !ok || e < 0 || e >= len(s) {
+				return nil
+			}
+			v = s[e]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// stringMap converts a mapping such as metadata.labels into a map of strings
+func stringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	s := make(map[string]string, len(m))
+	for k, v := range m {
+		s[k] = fmt.Sprintf("%v", v)
+	}
+	return s
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinPath returns a new path composed of base followed by elements
+func joinPath(base []interface{}, elements ...interface{}) []interface{} {
+	path := make([]interface{}, 0, len(base)+len(elements))
+	path = append(path, base...)
+	return append(path, elements...)
+}
+
+// podTemplatePath returns the path to the pod template of workload resources.
+// The template of a Pod is the Pod itself.
+func (r *Resource) podTemplatePath() ([]interface{}, bool) {
+	switch r.Kind() {
+	case "Pod":
+		return []interface{}{}, true
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return []interface{}{"spec", "template"}, true
+	case "CronJob":
+		return []interface{}{"spec", "jobTemplate", "spec", "template"}, true
+	}
+	return nil, false
+}
+
+// podLabels returns the labels applied to the pods of workload resources
+func (r *Resource) podLabels() map[string]string {
+	template, ok := r.podTemplatePath()
+	if !ok {
+		return nil
+	}
+	return stringMap(r.get(joinPath(template, "metadata", "labels")...))
+}
+
+// line returns the line in the Candidate's file on which the deepest element of
+// path is found.
+func (r *Resource) line(path ...interface{}) int {
+	if r.tokens == nil {
+		r.tokens = tokenizeYAML(r.bytes)
+	}
+	return r.offset + lineForPath(r.tokens, path)
+}
+
+// annotation returns an annotation on the line of the Candidate's file
+// containing path.
+func (r *Resource) annotation(level string, title string, message string, path ...interface{}) *github.CheckRunAnnotation {
+	line := r.line(path...)
+	return &github.CheckRunAnnotation{
+		Path:            r.candidate.file.Filename,
+		BlobHRef:        r.candidate.file.BlobURL,
+		StartLine:       github.Int(line),
+		EndLine:         github.Int(line),
+		AnnotationLevel: github.String(level),
+		Title:           github.String(title),
+		Message:         github.String(message),
+	}
+}
+
+// clusterScopedKinds are the kinds of built in and commonly installed
+// resources which aren't namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterIssuer":                  true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"GatewayClass":                   true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// namespaced returns false for resources of cluster scoped kinds
+func (r *Resource) namespaced() bool {
+	return !clusterScopedKinds[r.Kind()]
+}
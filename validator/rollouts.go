@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const rollingUpdateTitle = "Incoherent rolling update"
+
+// defaultRollingUpdateValue is used by Kubernetes for maxSurge and
+// maxUnavailable when a Deployment's rollingUpdate doesn't set them
+const defaultRollingUpdateValue = "25%"
+
+// resolveIntOrPercent resolves v, an integer or a percentage such as "25%",
+// against total. Percentages are rounded up when roundUp is set and down
+// otherwise, like Kubernetes does for maxSurge and maxUnavailable.
+func resolveIntOrPercent(v interface{}, total int, roundUp bool) (int, bool) {
+	if i, ok := intValue(v); ok {
+		return i, true
+	}
+	s, ok := v.(string)
+	if !ok || !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil {
+		return 0, false
+	}
+	resolved := float64(percent) * float64(total) / 100
+	if roundUp {
+		return int(math.Ceil(resolved)), true
+	}
+	return int(math.Floor(resolved)), true
+}
+
+// replicaCount returns the number of replicas of a Deployment. Deployments
+// without replicas run a single one unless an autoscaler targets them, in
+// which case the count isn't known.
+func (r *Resource) replicaCount(resources []*Resource) (int, bool) {
+	if v := r.get("spec", "replicas"); v != nil {
+		return intValue(v)
+	}
+	if r.autoscaler(resources) != nil {
+		return 0, false
+	}
+	return 1, true
+}
+
+// checkRollingUpdate warns when the maxSurge and maxUnavailable of a
+// Deployment's rolling update are incoherent with its replicas: when neither
+// allows a pod to be replaced, or when every replica may be unavailable at
+// once.
+func checkRollingUpdate(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "Deployment" {
+		return nil
+	}
+	if strategy := r.getString("spec", "strategy", "type"); strategy != "" && strategy != "RollingUpdate" {
+		return nil
+	}
+	level := r.policies().RollingUpdates.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+	replicas, ok := r.replicaCount(resources)
+	if !ok || replicas < 1 {
+		return nil
+	}
+
+	rollingUpdate := []interface{}{"spec", "strategy", "rollingUpdate"}
+	maxSurge := r.get(joinPath(rollingUpdate, "maxSurge")...)
+	if maxSurge == nil {
+		maxSurge = defaultRollingUpdateValue
+	}
+	maxUnavailable := r.get(joinPath(rollingUpdate, "maxUnavailable")...)
+	if maxUnavailable == nil {
+		maxUnavailable = defaultRollingUpdateValue
+	}
+	surge, ok := resolveIntOrPercent(maxSurge, replicas, true)
+	if !ok {
+		return nil
+	}
+	unavailable, ok := resolveIntOrPercent(maxUnavailable, replicas, false)
+	if !ok {
+		return nil
+	}
+
+	if surge == 0 && unavailable == 0 {
+		return Annotations{r.annotation(level, rollingUpdateTitle,
+			fmt.Sprintf("%s sets maxSurge to %v and maxUnavailable to %v, which resolve to 0 pods with %d replicas. A rollout can't create or remove a pod without exceeding them.", r, maxSurge, maxUnavailable, replicas),
+			rollingUpdate...)}
+	}
+	if unavailable >= replicas {
+		message := fmt.Sprintf("%s sets maxUnavailable to %v, which allows all %d replicas to be unavailable during a rollout.", r, maxUnavailable, replicas)
+		if surge == 0 {
+			message += " With maxSurge 0, every pod is stopped before its replacement is ready."
+		}
+		return Annotations{r.annotation(level, rollingUpdateTitle, message, joinPath(rollingUpdate, "maxUnavailable")...)}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestRollingUpdate(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/rollouts/rolling-update.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/rollouts/rolling-update.yaml"),
+		StartLine:       github.Int(11),
+		AnnotationLevel: github.String("warning"),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/rollouts/rolling-update.yaml"),
+		StartLine:       github.Int(31),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestRollingUpdateCanBeTurnedOff(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RollingUpdates: &KubeValidatorConfigRule{Level: "off"},
+	}, "fixtures/checks/rollouts/rolling-update.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestResolveIntOrPercent(t *testing.T) {
+	for _, test := range []struct {
+		value   interface{}
+		total   int
+		roundUp bool
+		want    int
+	}{
+		{1, 3, false, 1},
+		{"25%", 3, false, 0},
+		{"25%", 3, true, 1},
+		{"100%", 1, false, 1},
+	} {
+		got, ok := resolveIntOrPercent(test.value, test.total, test.roundUp)
+		if !ok || got != test.want {
+			t.Errorf("resolveIntOrPercent(%v, %d, %v) = %d, %v, want %d", test.value, test.total, test.roundUp, got, ok, test.want)
+		}
+	}
+	if _, ok := resolveIntOrPercent("a lot", 3, false); ok {
+		t.Error("expected a value that isn't an integer or percentage not to resolve")
+	}
+}
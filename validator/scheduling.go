@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	priorityClassTitle    = "Priority class not allowed"
+	hostPortConflictTitle = "Conflicting hostPort"
+)
+
+// checkPriorityClassName fails when a workload of the configured kinds doesn't
+// set a priorityClassName from the allowed list.
+func checkPriorityClassName(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().PriorityClassNames
+	if policy == nil {
+		return nil
+	}
+	spec, ok := r.podSpecPath()
+	if !ok || (len(policy.Kinds) > 0 && !containsString(policy.Kinds, r.Kind())) {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	allowed := strings.Join(policy.Allowed, ", ")
+	priorityClassName, _ := r.get(joinPath(spec, "priorityClassName")...).(string)
+	if priorityClassName == "" {
+		return Annotations{r.annotation(level, priorityClassTitle,
+			fmt.Sprintf("%s must set priorityClassName to one of: %s", r, allowed),
+			spec...)}
+	}
+	if !containsString(policy.Allowed, priorityClassName) {
+		return Annotations{r.annotation(level, priorityClassTitle,
+			fmt.Sprintf("%s uses the %s priority class, which isn't one of: %s", r, priorityClassName, allowed),
+			joinPath(spec, "priorityClassName")...)}
+	}
+	return nil
+}
+
+// hostPort is a port of a container which is bound on the node
+type hostPort struct {
+	resource *Resource
+	path     []interface{}
+	port     string
+}
+
+// hostPorts returns every protocol/port bound on the node by the containers
+// of a workload
+func (r *Resource) hostPorts() []hostPort {
+	var ports []hostPort
+	for _, c := range r.containers() {
+		list, _ := c.get("ports").([]interface{})
+		for i := range list {
+			port := c.get("ports", i, "hostPort")
+			if port == nil {
+				continue
+			}
+			protocol, _ := c.get("ports", i, "protocol").(string)
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			ports = append(ports, hostPort{
+				resource: r,
+				path:     joinPath(c.path, "ports", i, "hostPort"),
+				port:     fmt.Sprintf("%v/%s", port, protocol),
+			})
+		}
+	}
+	return ports
+}
+
+// checkHostPortConflicts warns when more than one workload binds the same
+// hostPort, as their pods can't be scheduled on the same node.
+func checkHostPortConflicts(r *Resource, resources []*Resource) Annotations {
+	level := r.policies().HostPortConflicts.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, port := range r.hostPorts() {
+		var others []string
+		for _, other := range resources {
+			if other == r {
+				continue
+			}
+			for _, otherPort := range other.hostPorts() {
+				if otherPort.port == port.port {
+					others = append(others, other.String())
+					break
+				}
+			}
+		}
+		if len(others) > 0 {
+			annotations = append(annotations, r.annotation(level, hostPortConflictTitle,
+				fmt.Sprintf("%s binds hostPort %s, as does %s. Their pods can't be scheduled on the same node.", r, port.port, strings.Join(others, ", ")),
+				port.path...))
+		}
+	}
+	return annotations
+}
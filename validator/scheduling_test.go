@@ -0,0 +1,32 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestPriorityClassNames(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		PriorityClassNames: &KubeValidatorConfigPriorityClassNames{
+			Kinds:   []string{"Deployment"},
+			Allowed: []string{"production"},
+		},
+	}, "fixtures/checks/scheduling/priority-class.yaml")
+
+	path := github.String("fixtures/checks/scheduling/priority-class.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(13), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(31), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestHostPortConflicts(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/scheduling/host-ports.yaml")
+
+	path := github.String("fixtures/checks/scheduling/host-ports.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(19), AnnotationLevel: github.String("warning")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(42), AnnotationLevel: github.String("warning")},
+	)
+}
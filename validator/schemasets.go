@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// schemaSetResults returns the names of the schema sets the Candidates were
+// validated against, in the order they were first referenced, along with
+// whether each set passed
+func (c *Candidates) schemaSetResults() ([]string, map[string]bool) {
+	var names []string
+	passed := map[string]bool{}
+	for _, candidate := range *c {
+		for _, set := range candidate.schemaSets {
+			if _, ok := passed[set.Name]; !ok {
+				names = append(names, set.Name)
+				passed[set.Name] = true
+			}
+			if len(candidate.schemaSetAnnotations[set.Name]) > 0 {
+				passed[set.Name] = false
+			}
+		}
+	}
+	return names, passed
+}
+
+// schemaSetConclusion returns the configured schema set conclusion
+func (c *Candidates) schemaSetConclusion() string {
+	for _, candidate := range *c {
+		if candidate.schemaSetConclusion != "" {
+			return candidate.schemaSetConclusion
+		}
+	}
+	return schemaSetConclusionAll
+}
+
+// applySchemaSetConclusion downgrades the failures of schema sets which didn't
+// pass to warnings when only one set needs to pass and another did
+func (c *Candidates) applySchemaSetConclusion() {
+	if c.schemaSetConclusion() != schemaSetConclusionAny {
+		return
+	}
+	names, passed := c.schemaSetResults()
+	anyPassed := false
+	for _, name := range names {
+		anyPassed = anyPassed || passed[name]
+	}
+	if !anyPassed {
+		return
+	}
+	for _, candidate := range *c {
+		for _, annotations := range candidate.schemaSetAnnotations {
+			for _, annotation := range annotations {
+				if annotation.GetAnnotationLevel() == levelFailure {
+					annotation.AnnotationLevel = github.String(levelWarning)
+				}
+			}
+		}
+	}
+}
+
+// schemaSetsMarkdown returns a Markdown table reporting whether each schema
+// set passed
+func (c *Candidates) schemaSetsMarkdown() string {
+	names, passed := c.schemaSetResults()
+	if len(names) == 0 {
+		return ""
+	}
+	lines := []string{"| Schema set | Result |", "| --- | --- |"}
+	for _, name := range names {
+		result := "Passed"
+		if !passed[name] {
+			result = "Failed"
+		}
+		lines = append(lines, fmt.Sprintf("| %s | %s |", name, result))
+	}
+	if c.schemaSetConclusion() == schemaSetConclusionAny {
+		lines = append(lines, "", "Only one schema set needs to pass.")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// schemaSetCandidates returns the candidates matching a schema set fixture
+// validated against local current and next schema sets
+func schemaSetCandidates(t *testing.T, conclusion string, path string) Candidates {
+	var sets []*KubeValidatorConfigSchemaSet
+	for _, name := range []string{"current", "next"} {
+		location, _ := filepath.Abs(filepath.Join("../fixtures/schema-sets", name))
+		sets = append(sets, &KubeValidatorConfigSchemaSet{
+			Name:    name,
+			Schemas: []*KubeValidatorConfigSchema{{Version: "master", Location: "file://" + location}},
+		})
+	}
+	config := &KubeValidatorConfig{
+		Spec: &KubeValidatorConfigSpec{
+			Manifests: []*KubeValidatorConfigManifest{{
+				Glob:       "fixtures/checks/schema-sets/*.yaml",
+				SchemaSets: []string{"current", "next"},
+			}},
+			SchemaSets:          sets,
+			SchemaSetConclusion: conclusion,
+		},
+	}
+	if !config.Valid() {
+		t.Fatal("expected the config to be valid")
+	}
+
+	candidates := Candidates(config.matchingCandidates(&Context{Event: &github.CheckSuiteEvent{}}, []*github.CommitFile{{Filename: github.String(path)}}))
+	b, err := ioutil.ReadFile(filepath.Join("..", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, candidate := range candidates {
+		candidate.setBytes(&b)
+	}
+	candidates.allowSchemaLocations(func(string) bool { return true })
+	return candidates
+}
+
+func TestSchemaSetsReportEachSet(t *testing.T) {
+	candidates := schemaSetCandidates(t, "", "fixtures/checks/schema-sets/configmap.yaml")
+	wantAnnotations(t, candidates.Validate(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/schema-sets/configmap.yaml"),
+		StartLine:       github.Int(1),
+		AnnotationLevel: github.String(levelFailure),
+	})
+
+	summary := candidates.schemaSetsMarkdown()
+	for _, want := range []string{"| current | Passed |", "| next | Failed |"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected %q in %s", want, summary)
+		}
+	}
+}
+
+func TestSchemaSetsAnyConclusion(t *testing.T) {
+	candidates := schemaSetCandidates(t, schemaSetConclusionAny, "fixtures/checks/schema-sets/configmap.yaml")
+	annotations := candidates.Validate()
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/schema-sets/configmap.yaml"),
+		StartLine:       github.Int(1),
+		AnnotationLevel: github.String(levelWarning),
+	})
+	if !strings.Contains(annotations[0].GetTitle(), "next/master") {
+		t.Errorf("expected the annotation to name the schema set, got %s", annotations[0].GetTitle())
+	}
+}
+
+// Sorting annotations mustn't change those recorded for each schema set, or
+// the wrong annotation is downgraded
+func TestSchemaSetsAnyConclusionAfterSorting(t *testing.T) {
+	candidates := schemaSetCandidates(t, schemaSetConclusionAny, "fixtures/checks/schema-sets/ordering.yaml")
+	wantAnnotations(t, candidates.Validate(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/schema-sets/ordering.yaml"),
+		StartLine:       github.Int(10),
+		AnnotationLevel: github.String(levelWarning),
+	}, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/schema-sets/ordering.yaml"),
+		StartLine:       github.Int(1),
+		AnnotationLevel: github.String(levelFailure),
+	})
+}
+
+func TestUndefinedSchemaSetIsInvalid(t *testing.T) {
+	config := &KubeValidatorConfig{
+		Spec: &KubeValidatorConfigSpec{
+			Manifests: []*KubeValidatorConfigManifest{{Glob: "*.yaml", SchemaSets: []string{"missing"}}},
+		},
+	}
+	if config.Valid() {
+		t.Error("expected a reference to an undefined schema set to be invalid")
+	}
+}
+
+func TestSchemaLocationsMustBeAllowed(t *testing.T) {
+	candidates := schemaSetCandidates(t, "", "fixtures/checks/schema-sets/configmap.yaml")
+	candidates.allowSchemaLocations((&Context{}).schemaLocationAllowed)
+
+	annotations := candidates.Validate()
+	if len(annotations) != 2 {
+		t.Fatalf("expected an annotation for each schema set, got %s", github.Stringify(annotations))
+	}
+	for _, annotation := range annotations {
+		if annotation.GetTitle() != schemaLocationNotAllowedTitle || strings.Contains(annotation.GetMessage(), "file://") {
+			t.Errorf("expected the location not to be allowed without echoing it, got %s", github.Stringify(annotation))
+		}
+	}
+
+	location, _ := filepath.Abs("../fixtures/schema-sets")
+	candidates.allowSchemaLocations((&Context{SchemaLocations: []string{"file://" + location + "/**"}}).schemaLocationAllowed)
+	for _, annotation := range candidates.Validate() {
+		if annotation.GetTitle() == schemaLocationNotAllowedTitle {
+			t.Errorf("expected locations matching the allowed globs to be loaded, got %s", github.Stringify(annotation))
+		}
+	}
+}
+
+func TestSchemaLocationsMustBeHTTPSOrFiles(t *testing.T) {
+	for location, want := range map[string]bool{
+		"https://schemas.example.com":   true,
+		"file:///schemas":               true,
+		"http://169.254.169.254/latest": false,
+		"gopher://schemas.example.com":  false,
+		"/etc/kubevalidator/schemas":    false,
+	} {
+		config := &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{
+			SchemaSets: []*KubeValidatorConfigSchemaSet{{
+				Name:    "current",
+				Schemas: []*KubeValidatorConfigSchema{{Location: location}},
+			}},
+		}}
+		if got := config.Valid(); got != want {
+			t.Errorf("%s: expected the config to be valid to be %v, got %v", location, want, got)
+		}
+	}
+}
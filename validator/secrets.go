@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const secretTypeKeysTitle = "Secret is missing required keys"
+
+// secretTypeKeys lists the keys required by built in Secret types. At least
+// one key of each inner list must be present.
+// https://kubernetes.io/docs/concepts/configuration/secret/#secret-types
+var secretTypeKeys = map[string][][]string{
+	"kubernetes.io/basic-auth":       {{"username", "password"}},
+	"kubernetes.io/dockercfg":        {{".dockercfg"}},
+	"kubernetes.io/dockerconfigjson": {{".dockerconfigjson"}},
+	"kubernetes.io/ssh-auth":         {{"ssh-privatekey"}},
+	"kubernetes.io/tls":              {{"tls.crt"}, {"tls.key"}},
+}
+
+// checkSecretTypeKeys fails when a Secret doesn't contain the keys required by
+// its type in either data or stringData.
+func checkSecretTypeKeys(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "Secret" {
+		return nil
+	}
+	level := r.policies().SecretTypeKeys.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	secretType := r.getString("type")
+	path := []interface{}{"data"}
+	if r.get("data") == nil {
+		path = []interface{}{"type"}
+	}
+
+	var annotations Annotations
+	for _, keys := range secretTypeKeys[secretType] {
+		if r.hasSecretKey(keys...) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, secretTypeKeysTitle,
+			fmt.Sprintf("%s has type %s, which requires the %s key in data or stringData.", r, secretType, strings.Join(keys, " or ")),
+			path...))
+	}
+	return annotations
+}
+
+// hasSecretKey returns true when any of keys is present in the data or
+// stringData of a Secret
+func (r *Resource) hasSecretKey(keys ...string) bool {
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := r.get(field).(map[string]interface{})
+		for _, key := range keys {
+			if _, ok := values[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestTLSSecretMissingKey(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/secrets/tls.yaml")
+	annotations := candidates.Check()
+
+	wantAnnotations(t, annotations, &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/secrets/tls.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("failure"),
+	})
+	if len(annotations) == 1 && !strings.Contains(annotations[0].GetMessage(), "tls.key") {
+		t.Errorf("expected the missing tls.key to be reported, got %s", annotations[0].GetMessage())
+	}
+}
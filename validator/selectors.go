@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	immutableSelectorTitle = "Selector changed"
+	selectorLabelsTitle    = "Selector doesn't match pod template"
+)
+
+// immutableSelectorKinds are the kinds whose spec.selector can't be changed
+// once created
+var immutableSelectorKinds = map[string]bool{
+	"DaemonSet":   true,
+	"Deployment":  true,
+	"ReplicaSet":  true,
+	"StatefulSet": true,
+}
+
+// checkImmutableSelector fails when the selector of a workload differs from
+// its selector in the base of the Pull Request, as the API server will reject
+// the change.
+func checkImmutableSelector(r *Resource, resources []*Resource) Annotations {
+	if !immutableSelectorKinds[r.Kind()] {
+		return nil
+	}
+	level := r.policies().ImmutableSelectors.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	base := r.candidate.baseResource(r)
+	if base == nil || base.get("spec", "selector") == nil {
+		return nil
+	}
+	if reflect.DeepEqual(base.get("spec", "selector"), r.get("spec", "selector")) {
+		return nil
+	}
+	return Annotations{r.annotation(level, immutableSelectorTitle,
+		fmt.Sprintf("The selector of %s can't be changed once it has been created. Delete and recreate it, or revert the selector.", r),
+		"spec", "selector")}
+}
+
+// checkSelectorLabels fails when a label in the matchLabels of a workload's
+// selector isn't set to the same value in its pod template, which the API
+// server rejects.
+func checkSelectorLabels(r *Resource, resources []*Resource) Annotations {
+	if !immutableSelectorKinds[r.Kind()] && r.Kind() != "Job" {
+		return nil
+	}
+	level := r.policies().SelectorLabels.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	matchLabels := stringMap(r.get("spec", "selector", "matchLabels"))
+	keys := make([]string, 0, len(matchLabels))
+	for key := range matchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var annotations Annotations
+	labels := r.podLabels()
+	for _, key := range keys {
+		value, ok := labels[key]
+		if ok && value == matchLabels[key] {
+			continue
+		}
+		message := fmt.Sprintf("The pod template of %s doesn't have the %s label required by its selector.", r, key)
+		if ok {
+			message = fmt.Sprintf("The pod template of %s sets the %s label to %s, but its selector requires %s.", r, key, value, matchLabels[key])
+		}
+		annotations = append(annotations, r.annotation(level, selectorLabelsTitle, message,
+			"spec", "selector", "matchLabels", key))
+	}
+	return annotations
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestImmutableSelector(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/selectors/head.yaml")
+	filePath, _ := filepath.Abs("../fixtures/checks/selectors/base.yaml")
+	fileContents, _ := ioutil.ReadFile(filePath)
+	candidates[0].setBaseBytes(&fileContents)
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/selectors/head.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestImmutableSelectorWithoutBase(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/selectors/head.yaml")
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestImmutableSelectorLoadsBaseOnlyWhenEnabled(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	base, _ := ioutil.ReadFile("../fixtures/checks/selectors/base.yaml")
+	requests := 0
+	mux.HandleFunc("/repos/o/r/contents/fixtures/checks/selectors/head.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		requests++
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString(base))
+	})
+
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		ImmutableSelectors: &KubeValidatorConfigRule{Level: levelOff},
+	}, "fixtures/checks/selectors/head.yaml")
+	ctx := context.Background()
+	candidates[0].context = &Context{
+		Ctx:    &ctx,
+		Github: client,
+		Event: &github.CheckSuiteEvent{
+			Repo: &github.Repository{Name: github.String("r"), Owner: &github.User{Login: github.String("o")}},
+			CheckSuite: &github.CheckSuite{PullRequests: []*github.PullRequest{{
+				Base: &github.PullRequestBranch{SHA: github.String("base")},
+			}}},
+		},
+	}
+	candidates[0].file.Status = github.String("modified")
+
+	wantAnnotations(t, candidates.Check())
+	if requests != 0 {
+		t.Errorf("expected the base not to be loaded while immutableSelectors is off, got %d requests", requests)
+	}
+
+	candidates[0].policies = nil
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/selectors/head.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("failure"),
+	})
+	if requests != 1 {
+		t.Errorf("expected the base to be loaded once, got %d requests", requests)
+	}
+}
+
+func TestImmutableSelectorComparesRenamedFiles(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+	base, _ := ioutil.ReadFile("../fixtures/checks/selectors/base.yaml")
+	mux.HandleFunc("/repos/o/r/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"filename": "fixtures/checks/selectors/head.yaml", "status": "renamed", "previous_filename": "deploy/head.yaml"}]`)
+	})
+	mux.HandleFunc("/repos/o/r/contents/deploy/head.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString(base))
+	})
+
+	ctx := context.Background()
+	e := &github.CheckSuiteEvent{
+		Repo: &github.Repository{Name: github.String("r"), Owner: &github.User{Login: github.String("o")}},
+		CheckSuite: &github.CheckSuite{PullRequests: []*github.PullRequest{{
+			Number: github.Int(1),
+			Base:   &github.PullRequestBranch{SHA: github.String("base")},
+		}}},
+	}
+	c := &Context{Ctx: &ctx, Github: client, Event: e}
+	files, err := c.changedFileList(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/selectors/head.yaml")
+	candidates[0].context = c
+	candidates[0].file = files[0]
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/selectors/head.yaml"),
+		StartLine:       github.Int(6),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestSelectorLabels(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/selectors/labels.yaml")
+
+	path := github.String("fixtures/checks/selectors/labels.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(10), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(9), AnnotationLevel: github.String("failure")},
+	)
+}
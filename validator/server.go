@@ -6,20 +6,55 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation"
 	"github.com/google/go-github/github"
 )
 
+// permissionsRecheckInterval is how often readiness checks verify the
+// permissions of the GitHub App again until they've been granted
+const permissionsRecheckInterval = time.Minute
+
 // Server contains the logic to process webhooks, kinda like probot
 type Server struct {
 	Port            int
 	WebhookSecret   string
 	PrivateKeyFile  string
 	AppID           int
+	EnabledHandlers []string
 	GitHubAppClient *github.Client
 	tr              *http.RoundTripper
 	ctx             *context.Context
+
+	// LatestCheckSuiteOnly ignores check suites for superseded Pull Request
+	// heads
+	LatestCheckSuiteOnly bool
+
+	// ImagePolicySources are globs matching the image policy files and URLs
+	// repositories may configure
+	ImagePolicySources []string
+
+	// SchemaLocations are globs matching the schema locations repositories
+	// may configure
+	SchemaLocations []string
+
+	// GistToken is a personal access token used to upload reports too long
+	// for a check run summary to secret Gists. Long summaries are truncated
+	// when it's empty.
+	GistToken string
+
+	// CheckPermissions verifies that the GitHub App has been granted the
+	// permissions kubevalidator needs on startup. The server isn't ready
+	// until it has, and readiness checks verify them again at most every
+	// permissionsRecheckInterval until then.
+	CheckPermissions     bool
+	ready                int32
+	permissionsMu        sync.Mutex
+	permissionsCheckedAt time.Time
 }
 
 // GenericEvent contains just enough inforamation about webhook to handle
@@ -33,6 +68,9 @@ type GenericEvent struct {
 
 // Run starts a http server on the configured port
 func (s *Server) Run(ctx context.Context) error {
+	if err := validHandlers(s.EnabledHandlers); err != nil {
+		return err
+	}
 	s.tr = &http.DefaultTransport
 
 	itr, err := ghinstallation.NewAppsTransportKeyFromFile(*s.tr, s.AppID, s.PrivateKeyFile)
@@ -42,9 +80,11 @@ func (s *Server) Run(ctx context.Context) error {
 
 	s.ctx = &ctx
 	s.GitHubAppClient = github.NewClient(&http.Client{Transport: itr})
+	s.checkPermissions(ctx)
 
 	http.HandleFunc("/webhook", s.handle)
 	http.HandleFunc("/healthz", s.health)
+	http.HandleFunc("/readyz", s.readiness)
 	http.HandleFunc("/", s.redirect)
 	log.Println("hi")
 	return http.ListenAndServe(fmt.Sprintf(":%d", s.Port), nil)
@@ -86,10 +126,22 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		AppID:     &s.AppID,
 		Github:    github.NewClient(&http.Client{Transport: installationTransport}),
 		AppGitHub: s.GitHubAppClient,
+
+		EnabledHandlers:      s.EnabledHandlers,
+		LatestCheckSuiteOnly: s.LatestCheckSuiteOnly,
+		ImagePolicySources:   s.ImagePolicySources,
+		SchemaLocations:      s.SchemaLocations,
+		DeliveryID:           github.DeliveryID(r),
+	}
+	if s.GistToken != "" {
+		c.GistGithub = NewGistClient(s.GistToken)
 	}
 
 	// TODO Return a 500 if we don't make it through the complete CheckRun cycle
 	c.Process()
+	if c.panicked {
+		http.Error(w, "Couldn't process delivery", http.StatusInternalServerError)
+	}
 	return
 }
 
@@ -98,6 +150,51 @@ func (s *Server) health(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "hi")
 }
 
+// checkPermissions marks the server as ready unless CheckPermissions is set
+// and the GitHub App is missing permissions, or they couldn't be determined
+func (s *Server) checkPermissions(ctx context.Context) {
+	if !s.CheckPermissions {
+		atomic.StoreInt32(&s.ready, 1)
+		return
+	}
+	s.permissionsMu.Lock()
+	s.permissionsCheckedAt = now()
+	s.permissionsMu.Unlock()
+	missing, err := missingPermissions(ctx, s.GitHubAppClient)
+	if err != nil {
+		log.Printf("%+v\n", err)
+		return
+	}
+	if len(missing) > 0 {
+		log.Printf("WARNING: the GitHub App is missing required permissions, update them in its settings: %s\n", strings.Join(missing, ", "))
+		return
+	}
+	atomic.StoreInt32(&s.ready, 1)
+}
+
+// permissionsCheckDue returns true when the permissions of the GitHub App were
+// last checked at least permissionsRecheckInterval ago
+func (s *Server) permissionsCheckDue() bool {
+	s.permissionsMu.Lock()
+	defer s.permissionsMu.Unlock()
+	return now().Sub(s.permissionsCheckedAt) >= permissionsRecheckInterval
+}
+
+// readiness fails until the permissions of the GitHub App have been verified,
+// checking them again while they haven't been so the server becomes ready once
+// they're granted or GitHub is reachable. They're checked at most every
+// permissionsRecheckInterval, however often the server is probed.
+func (s *Server) readiness(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&s.ready) == 0 && s.permissionsCheckDue() {
+		s.checkPermissions(r.Context())
+	}
+	if atomic.LoadInt32(&s.ready) == 0 {
+		http.Error(w, "The GitHub App is missing required permissions", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "ready")
+}
+
 func (s *Server) redirect(w http.ResponseWriter, r *http.Request) {
 	// TODO automatically generate this redirect
 	http.Redirect(w, r, "http://github.com/urcomputeringpal/kubevalidator", 301)
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const (
+	orphanedServiceTitle       = "Service has no matching workload"
+	disallowedServiceTypeTitle = "Service type not allowed"
+	nodePortRangeTitle         = "nodePort out of range"
+)
+
+// checkOrphanedService warns when the selector of a Service doesn't match the
+// pods of any workload being validated. The workload may already exist in the
+// cluster, so this check must be enabled explicitly.
+func checkOrphanedService(r *Resource, resources []*Resource) Annotations {
+	rule := r.policies().OrphanedServices
+	if rule == nil || r.Kind() != "Service" {
+		return nil
+	}
+	level := rule.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	selector := stringMap(r.get("spec", "selector"))
+	if len(selector) == 0 {
+		return nil
+	}
+
+	for _, workload := range resources {
+		if workload.Namespace() != r.Namespace() {
+			continue
+		}
+		if labelsMatch(selector, workload.podLabels()) {
+			return nil
+		}
+	}
+
+	return Annotations{r.annotation(level, orphanedServiceTitle,
+		fmt.Sprintf("The selector of %s (%s) doesn't match the pods of any workload in this Pull Request.", r, formatLabels(selector)),
+		"spec", "selector")}
+}
+
+// labelsMatch returns true when every label in selector is present in labels
+func labelsMatch(selector map[string]string, labels map[string]string) bool {
+	if len(selector) == 0 || labels == nil {
+		return false
+	}
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabels returns labels in the sorted k=v,k=v form used by kubectl
+func formatLabels(labels map[string]string) string {
+	var pairs []string
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// allowedServiceTypes returns the Service types allowed in namespace, using
+// the types of the first namespace rule matching it
+func (policy *KubeValidatorConfigAllowedServiceTypes) allowedServiceTypes(namespace string) []string {
+	if namespace != "" {
+		for _, rule := range policy.Namespaces {
+			for _, glob := range rule.Namespaces {
+				if matched, _ := doublestar.Match(glob, namespace); matched {
+					return rule.Types
+				}
+			}
+		}
+	}
+	return policy.Types
+}
+
+// checkAllowedServiceTypes fails when a Service uses a type that isn't allowed
+// in its namespace. Services without a type are ClusterIP Services.
+func checkAllowedServiceTypes(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().AllowedServiceTypes
+	if policy == nil || r.Kind() != "Service" {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	allowed := policy.allowedServiceTypes(r.Namespace())
+	path := []interface{}{"spec", "type"}
+	serviceType := r.getString(path...)
+	if serviceType == "" {
+		serviceType = "ClusterIP"
+		path = []interface{}{"spec"}
+	}
+	if containsString(allowed, serviceType) {
+		return nil
+	}
+
+	location := "this namespace"
+	if r.Namespace() != "" {
+		location = fmt.Sprintf("the %s namespace", r.Namespace())
+	}
+	return Annotations{r.annotation(level, disallowedServiceTypeTitle,
+		fmt.Sprintf("%s is a %s Service, which isn't allowed in %s. Allowed types: %s", r, serviceType, location, strings.Join(allowed, ", ")),
+		path...)}
+}
+
+// checkNodePortRange fails when a Service sets a nodePort outside of the
+// cluster's node port range, which the API server rejects.
+func checkNodePortRange(r *Resource, resources []*Resource) Annotations {
+	if r.Kind() != "Service" {
+		return nil
+	}
+	policy := r.policies().NodePorts
+	level := levelFailure
+	if policy != nil {
+		level = policy.level(levelFailure)
+	}
+	if level == "" {
+		return nil
+	}
+
+	min, max := policy.portRange()
+	var annotations Annotations
+	ports, _ := r.get("spec", "ports").([]interface{})
+	for i := range ports {
+		nodePort, ok := intValue(r.get("spec", "ports", i, "nodePort"))
+		if !ok || (nodePort >= min && nodePort <= max) {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, nodePortRangeTitle,
+			fmt.Sprintf("%s uses nodePort %d, outside of the allowed range %d-%d.", r, nodePort, min, max),
+			"spec", "ports", i, "nodePort"))
+	}
+	return annotations
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestOrphanedServiceWarns(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		OrphanedServices: &KubeValidatorConfigRule{},
+	}, "fixtures/checks/services/orphaned.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/services/orphaned.yaml"),
+		StartLine:       github.Int(35),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestOrphanedServiceIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/services/orphaned.yaml")
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestAllowedServiceTypes(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		AllowedServiceTypes: &KubeValidatorConfigAllowedServiceTypes{
+			Types: []string{"ClusterIP", "LoadBalancer"},
+			Namespaces: []*KubeValidatorConfigNamespaceTypes{
+				{Namespaces: []string{"dev-*"}, Types: []string{"ClusterIP"}},
+			},
+		},
+	}, "fixtures/checks/services/types.yaml")
+
+	path := github.String("fixtures/checks/services/types.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(18), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(30), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestNodePortRange(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/services/node-ports.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/services/node-ports.yaml"),
+		StartLine:       github.Int(12),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestNodePortRangeIsConfigurable(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		NodePorts: &KubeValidatorConfigNodePorts{Min: 8000, Max: 30100},
+	}, "fixtures/checks/services/node-ports.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/services/node-ports.yaml"),
+		StartLine:       github.Int(15),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestInvalidNodePortRange(t *testing.T) {
+	policies := &KubeValidatorConfigPolicies{
+		NodePorts: &KubeValidatorConfigNodePorts{Min: 32000, Max: 31000},
+	}
+	if policies.valid() {
+		t.Error("expected a range whose minimum exceeds its maximum to be invalid")
+	}
+}
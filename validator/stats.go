@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+)
+
+// ruleStats counts the annotations produced by a rule, identified by the
+// title of its annotations
+type ruleStats struct {
+	Rule   string         `json:"rule"`
+	Count  int            `json:"count"`
+	Levels map[string]int `json:"levels"`
+}
+
+// stats summarizes annotations by rule, and optionally by directory
+type stats struct {
+	Annotations int                     `json:"annotations"`
+	Rules       []*ruleStats            `json:"rules"`
+	Directories map[string][]*ruleStats `json:"directories,omitempty"`
+}
+
+// countRules returns the number of annotations produced by each rule, most
+// frequent first
+func countRules(annotations Annotations) []*ruleStats {
+	byRule := map[string]*ruleStats{}
+	rules := []*ruleStats{}
+	for _, a := range annotations {
+		rule, ok := byRule[a.GetTitle()]
+		if !ok {
+			rule = &ruleStats{Rule: a.GetTitle(), Levels: map[string]int{}}
+			byRule[a.GetTitle()] = rule
+			rules = append(rules, rule)
+		}
+		rule.Count++
+		rule.Levels[a.GetAnnotationLevel()]++
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+	return rules
+}
+
+// newStats summarizes annotations, including a summary of each directory
+// containing annotated files when byDirectory is true
+func newStats(annotations Annotations, byDirectory bool) *stats {
+	s := &stats{
+		Annotations: len(annotations),
+		Rules:       countRules(annotations),
+	}
+	if !byDirectory {
+		return s
+	}
+	directories := map[string]Annotations{}
+	for _, a := range annotations {
+		dir := path.Dir(a.GetPath())
+		directories[dir] = append(directories[dir], a)
+	}
+	s.Directories = map[string][]*ruleStats{}
+	for dir, a := range directories {
+		s.Directories[dir] = countRules(a)
+	}
+	return s
+}
+
+func writeStats(w io.Writer, annotations Annotations) error {
+	return writeStatsJSON(w, newStats(annotations, false))
+}
+
+func writeStatsByDirectory(w io.Writer, annotations Annotations) error {
+	return writeStatsJSON(w, newStats(annotations, true))
+}
+
+func writeStatsJSON(w io.Writer, s *stats) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// statsConfig writes a configuration validating the stats fixture tree against
+// local schemas and returns its path
+func statsConfig(t *testing.T) string {
+	schemas, _ := filepath.Abs("../fixtures/cli/schemas")
+	dir, err := ioutil.TempDir("", "kubevalidator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "kubevalidator.yaml")
+	config := fmt.Sprintf(`apiversion: v1alpha
+kind: KubeValidatorConfig
+spec:
+  manifests:
+  - glob: "**/*.yaml"
+    schemas:
+    - version: master
+      location: file://%s
+`, schemas)
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCLIStats(t *testing.T) {
+	config := statsConfig(t)
+	defer os.RemoveAll(filepath.Dir(config))
+
+	var out bytes.Buffer
+	cli := &CLI{
+		ConfigPath: config,
+		Root:       "../fixtures/cli/stats",
+		Format:     "stats-by-directory",
+		Out:        &out,
+	}
+	if _, err := cli.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got stats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%s: %s", err, out.String())
+	}
+	want := stats{
+		Annotations: 6,
+		Rules: []*ruleStats{
+			{Rule: duplicateEnvNameTitle, Count: 2, Levels: map[string]int{levelWarning: 2}},
+			{Rule: dataKeyTitle, Count: 2, Levels: map[string]int{levelFailure: 2}},
+			{Rule: duplicateContainerNameTitle, Count: 1, Levels: map[string]int{levelFailure: 1}},
+			{Rule: invalidScheduleTitle, Count: 1, Levels: map[string]int{levelFailure: 1}},
+		},
+		Directories: map[string][]*ruleStats{
+			"apps": {
+				{Rule: dataKeyTitle, Count: 2, Levels: map[string]int{levelFailure: 2}},
+				{Rule: duplicateContainerNameTitle, Count: 1, Levels: map[string]int{levelFailure: 1}},
+				{Rule: duplicateEnvNameTitle, Count: 1, Levels: map[string]int{levelWarning: 1}},
+			},
+			"jobs": {
+				{Rule: duplicateEnvNameTitle, Count: 1, Levels: map[string]int{levelWarning: 1}},
+				{Rule: invalidScheduleTitle, Count: 1, Levels: map[string]int{levelFailure: 1}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stats were\n%s", out.String())
+	}
+}
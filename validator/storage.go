@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	storageClassAccessModesTitle = "Access mode not supported by storage class"
+	maxStorageRequestTitle       = "Storage request too large"
+	missingStorageClassTitle     = "Storage class not set"
+)
+
+// claim is the spec of a PersistentVolumeClaim or of a StatefulSet's
+// volumeClaimTemplate
+type claim struct {
+	path []interface{}
+	spec map[string]interface{}
+}
+
+func (c *claim) get(path ...interface{}) interface{} {
+	return valueAt(c.spec, path)
+}
+
+// claims returns the specs of PersistentVolumeClaims and StatefulSet
+// volumeClaimTemplates
+func (r *Resource) claims() []*claim {
+	var paths [][]interface{}
+	switch r.Kind() {
+	case "PersistentVolumeClaim":
+		paths = append(paths, []interface{}{"spec"})
+	case "StatefulSet":
+		templates, _ := r.get("spec", "volumeClaimTemplates").([]interface{})
+		for i := range templates {
+			paths = append(paths, []interface{}{"spec", "volumeClaimTemplates", i, "spec"})
+		}
+	}
+
+	var claims []*claim
+	for _, path := range paths {
+		if spec, ok := r.get(path...).(map[string]interface{}); ok {
+			claims = append(claims, &claim{path: path, spec: spec})
+		}
+	}
+	return claims
+}
+
+// checkStorageClassAccessModes warns when a claim requests an access mode
+// which its storage class doesn't support according to the configured
+// capabilities. Claims of storage classes that aren't configured are skipped.
+func checkStorageClassAccessModes(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().StorageClassAccessModes
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelWarning)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.claims() {
+		storageClassName, _ := c.get("storageClassName").(string)
+		supported, ok := policy.StorageClasses[storageClassName]
+		if storageClassName == "" || !ok {
+			continue
+		}
+		modes, _ := c.get("accessModes").([]interface{})
+		for i, mode := range modes {
+			if containsString(supported, fmt.Sprintf("%v", mode)) {
+				continue
+			}
+			annotations = append(annotations, r.annotation(level, storageClassAccessModesTitle,
+				fmt.Sprintf("%s requests the %v access mode, but the %s storage class only supports %s.", r, mode, storageClassName, strings.Join(supported, ", ")),
+				joinPath(c.path, "accessModes", i)...))
+		}
+	}
+	return annotations
+}
+
+// checkMaxStorageRequest fails when a claim requests more storage than the
+// configured maximum.
+func checkMaxStorageRequest(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().MaxStorageRequests
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+	maximum, err := parseQuantity(policy.Maximum)
+	if err != nil {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.claims() {
+		storage := c.get("resources", "requests", "storage")
+		if storage == nil {
+			continue
+		}
+		path := joinPath(c.path, "resources", "requests", "storage")
+		requested, err := parseQuantity(storage)
+		if err != nil {
+			annotations = append(annotations, r.annotation(level, maxStorageRequestTitle,
+				fmt.Sprintf("%s requests storage of %v, which isn't a valid quantity.", r, storage),
+				path...))
+			continue
+		}
+		if requested.Cmp(maximum) > 0 {
+			annotations = append(annotations, r.annotation(level, maxStorageRequestTitle,
+				fmt.Sprintf("%s requests %v of storage, more than the maximum of %s.", r, storage, policy.Maximum),
+				path...))
+		}
+	}
+	return annotations
+}
+
+// checkStorageClassName fails when a claim doesn't set storageClassName, so
+// that it would be provisioned by whichever storage class is the cluster's
+// default. An empty storageClassName is explicit and allowed.
+func checkStorageClassName(r *Resource, resources []*Resource) Annotations {
+	policy := r.policies().RequireStorageClass
+	if policy == nil {
+		return nil
+	}
+	level := policy.level(levelFailure)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	for _, c := range r.claims() {
+		if _, ok := c.spec["storageClassName"]; ok {
+			continue
+		}
+		annotations = append(annotations, r.annotation(level, missingStorageClassTitle,
+			fmt.Sprintf("A claim of %s doesn't set storageClassName, so the cluster's default storage class would be used.", r),
+			c.path...))
+	}
+	return annotations
+}
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestStorageClassAccessModes(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		StorageClassAccessModes: &KubeValidatorConfigStorageClassAccessModes{
+			StorageClasses: map[string][]string{
+				"standard": {"ReadWriteOnce"},
+			},
+		},
+	}, "fixtures/checks/storage/access-modes.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/storage/access-modes.yaml"),
+		StartLine:       github.Int(8),
+		AnnotationLevel: github.String("warning"),
+	})
+}
+
+func TestMaxStorageRequest(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		MaxStorageRequests: &KubeValidatorConfigMaxStorageRequests{Maximum: "100Gi"},
+	}, "fixtures/checks/storage/requests.yaml")
+
+	path := github.String("fixtures/checks/storage/requests.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(10), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(47), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestInvalidMaxStorageRequest(t *testing.T) {
+	policies := &KubeValidatorConfigPolicies{
+		MaxStorageRequests: &KubeValidatorConfigMaxStorageRequests{Maximum: "lots"},
+	}
+	if policies.valid() {
+		t.Error("expected a maximum which isn't a quantity to be invalid")
+	}
+}
+
+func TestRequireStorageClass(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		RequireStorageClass: &KubeValidatorConfigSwitch{},
+	}, "fixtures/checks/storage/class-names.yaml")
+
+	wantAnnotations(t, candidates.Check(), &github.CheckRunAnnotation{
+		Path:            github.String("fixtures/checks/storage/class-names.yaml"),
+		StartLine:       github.Int(21),
+		AnnotationLevel: github.String("failure"),
+	})
+}
+
+func TestRequireStorageClassIsOptIn(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/storage/class-names.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
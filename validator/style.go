@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	trailingWhitespaceTitle = "Trailing whitespace"
+	tabIndentationTitle     = "Indented with tabs"
+	crlfLineEndingsTitle    = "CRLF line endings"
+)
+
+// styleAnnotations annotates lines ending with whitespace or indented with
+// tabs, and the first line ending with CRLF, when the whitespace policy is
+// enabled. These are notices by default so that they never fail the check.
+func (c *Candidate) styleAnnotations() Annotations {
+	policy := c.getPolicies().Whitespace
+	if policy == nil || c.bytes == nil {
+		return nil
+	}
+	level := policy.level(levelNotice)
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	crlf, firstCRLF := 0, 0
+	for i, line := range bytes.Split(*c.bytes, []byte("\n")) {
+		number := i + 1
+		if bytes.HasSuffix(line, []byte("\r")) {
+			crlf++
+			if firstCRLF == 0 {
+				firstCRLF = number
+			}
+			line = bytes.TrimSuffix(line, []byte("\r"))
+		}
+		if len(bytes.TrimRight(line, " \t")) != len(line) {
+			annotations = append(annotations, c.lineAnnotation(level, trailingWhitespaceTitle, "This line ends with whitespace.", number))
+		}
+		indentation := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if bytes.Contains(indentation, []byte("\t")) {
+			annotations = append(annotations, c.lineAnnotation(level, tabIndentationTitle, "This line is indented with a tab. Use spaces to indent YAML.", number))
+		}
+	}
+	if crlf > 0 {
+		annotations = append(annotations, c.lineAnnotation(level, crlfLineEndingsTitle,
+			fmt.Sprintf("%d lines of %s, starting with this one, end with CRLF. Use LF line endings.", crlf, c.file.GetFilename()), firstCRLF))
+	}
+	return annotations
+}
+
+// lineAnnotation returns an annotation on a line of the Candidate's file
+func (c *Candidate) lineAnnotation(level string, title string, message string, line int) *github.CheckRunAnnotation {
+	return &github.CheckRunAnnotation{
+		Path:            c.file.Filename,
+		BlobHRef:        c.file.BlobURL,
+		StartLine:       github.Int(line),
+		EndLine:         github.Int(line),
+		AnnotationLevel: github.String(level),
+		Title:           github.String(title),
+		Message:         github.String(message),
+	}
+}
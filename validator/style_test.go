@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestWhitespace(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		Whitespace: &KubeValidatorConfigRule{},
+	}, "fixtures/checks/style/whitespace.yaml", "fixtures/checks/style/crlf.yaml"))
+
+	annotations := candidates.Validate()
+	wantAnnotations(t, annotations,
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/style/crlf.yaml"), StartLine: github.Int(1), AnnotationLevel: github.String(levelNotice)},
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/style/whitespace.yaml"), StartLine: github.Int(3), AnnotationLevel: github.String(levelNotice)},
+		&github.CheckRunAnnotation{Path: github.String("fixtures/checks/style/whitespace.yaml"), StartLine: github.Int(8), AnnotationLevel: github.String(levelNotice)},
+	)
+	if annotations.Failed() {
+		t.Error("expected style annotations not to fail the check")
+	}
+}
+
+func TestWhitespaceIsOptIn(t *testing.T) {
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/style/whitespace.yaml", "fixtures/checks/style/crlf.yaml"))
+
+	wantAnnotations(t, candidates.Validate())
+}
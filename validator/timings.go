@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stages of processing a check suite reported by timings
+const (
+	stageCheckRun = "Creating the check run"
+	stageFiles    = "Fetching files"
+	stageSchemas  = "Validating schemas"
+	stagePolicies = "Running policies"
+
+	// failFast interleaves schemas and policies, so they're timed together
+	stageValidation = "Validating"
+)
+
+// timings accumulates the time spent in each stage of processing a check
+// suite, in the order the stages were first tracked
+type timings struct {
+	stages    []string
+	durations map[string]time.Duration
+}
+
+// track adds the time since start to stage
+func (t *timings) track(stage string, start time.Time) {
+	if t.durations == nil {
+		t.durations = map[string]time.Duration{}
+	}
+	if _, ok := t.durations[stage]; !ok {
+		t.stages = append(t.stages, stage)
+	}
+	t.durations[stage] += time.Since(start)
+}
+
+// markdown returns a table of the time spent in each stage and in total
+func (t *timings) markdown() string {
+	lines := []string{"### Timings", "", "| Stage | Time |", "| --- | ---: |"}
+	var total time.Duration
+	for _, stage := range t.stages {
+		lines = append(lines, fmt.Sprintf("| %s | %s |", stage, formatDuration(t.durations[stage])))
+		total += t.durations[stage]
+	}
+	lines = append(lines, fmt.Sprintf("| **Total** | **%s** |", formatDuration(total)))
+	return strings.Join(lines, "\n")
+}
+
+// formatDuration rounds d to the millisecond
+func formatDuration(d time.Duration) string {
+	return (d / time.Millisecond * time.Millisecond).String()
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingsMarkdown(t *testing.T) {
+	timings := &timings{}
+	start := time.Now()
+	timings.track(stageFiles, start)
+	timings.track(stageSchemas, start)
+	timings.track(stageFiles, start)
+
+	lines := strings.Split(timings.markdown(), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("expected a row for each stage and the total, got\n%s", timings.markdown())
+	}
+	if !strings.HasPrefix(lines[4], "| Fetching files |") || !strings.HasPrefix(lines[5], "| Validating schemas |") {
+		t.Errorf("expected stages in the order they were first tracked, got\n%s", timings.markdown())
+	}
+}
+
+func TestDebugTimingsInSummary(t *testing.T) {
+	for config, want := range map[string]bool{
+		"../fixtures/cli/kubevalidator.yaml":       false,
+		"../fixtures/cli/kubevalidator-debug.yaml": true,
+	} {
+		o, out, teardown := oneShotTestServer(t, config)
+		o.Format = "json"
+		if _, err := o.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		teardown()
+
+		var report oneShotReport
+		if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(report.Summary, "### Timings"); got != want {
+			t.Errorf("%s: expected timings in the summary to be %v, got\n%s", config, want, report.Summary)
+		}
+		for _, stage := range []string{stageCheckRun, stageFiles, stageSchemas, stagePolicies} {
+			if want && !strings.Contains(report.Summary, "| "+stage+" |") {
+				t.Errorf("expected the summary to time %s, got\n%s", stage, report.Summary)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+const unresolvedWebhookServiceTitle = "Webhook Service not found"
+
+// webhookConfigurationKinds lists the kinds of admission webhook
+// configurations
+var webhookConfigurationKinds = map[string]bool{
+	"ValidatingWebhookConfiguration": true,
+	"MutatingWebhookConfiguration":   true,
+}
+
+// service returns the Service in resources with namespace and name, if any. A
+// Service or a reference without a namespace matches any namespace, as it's
+// created in whichever namespace it's applied to.
+func service(namespace, name string, resources []*Resource) *Resource {
+	for _, r := range resources {
+		if r.Kind() != "Service" || r.Name() != name {
+			continue
+		}
+		if namespace == "" || r.Namespace() == "" || r.Namespace() == namespace {
+			return r
+		}
+	}
+	return nil
+}
+
+// servesPort returns true when a Service exposes port
+func (r *Resource) servesPort(port int) bool {
+	ports, _ := r.get("spec", "ports").([]interface{})
+	for i := range ports {
+		if p, ok := intValue(r.get("spec", "ports", i, "port")); ok && p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookServices fails when an admission webhook calls a Service that
+// isn't defined in the Pull Request or allowed explicitly, or a port the
+// Service doesn't expose. Depending on its failurePolicy, a webhook that
+// can't be reached rejects every matching request across the cluster.
+func checkWebhookServices(r *Resource, resources []*Resource) Annotations {
+	if !webhookConfigurationKinds[r.Kind()] {
+		return nil
+	}
+	policy := r.policies().WebhookServices
+	level := levelFailure
+	if policy != nil {
+		level = policy.level(levelFailure)
+	}
+	if level == "" {
+		return nil
+	}
+
+	var annotations Annotations
+	webhooks, _ := r.get("webhooks").([]interface{})
+	for i := range webhooks {
+		path := []interface{}{"webhooks", i, "clientConfig", "service"}
+		name := r.getString(joinPath(path, "name")...)
+		if name == "" {
+			continue
+		}
+		namespace := r.getString(joinPath(path, "namespace")...)
+		webhook := r.getString("webhooks", i, "name")
+		if policy.allowed(namespace, name) {
+			continue
+		}
+
+		svc := service(namespace, name, resources)
+		if svc == nil {
+			annotations = append(annotations, r.annotation(level, unresolvedWebhookServiceTitle,
+				fmt.Sprintf("The %s webhook of %s calls the Service %s/%s, which isn't defined in this Pull Request.", webhook, r, namespace, name),
+				joinPath(path, "name")...))
+			continue
+		}
+		port, portPath := 443, path
+		if p, ok := intValue(r.get(joinPath(path, "port")...)); ok {
+			port, portPath = p, joinPath(path, "port")
+		}
+		if !svc.servesPort(port) {
+			annotations = append(annotations, r.annotation(level, unresolvedWebhookServiceTitle,
+				fmt.Sprintf("The %s webhook of %s calls port %d of the Service %s/%s, which doesn't expose it.", webhook, r, port, namespace, name),
+				portPath...))
+		}
+	}
+	return annotations
+}
+
+// allowed returns true when namespace/name matches one of the globs of
+// Services known to exist outside of the Pull Request
+func (policy *KubeValidatorConfigWebhookServices) allowed(namespace, name string) bool {
+	if policy == nil {
+		return false
+	}
+	for _, glob := range policy.Services {
+		if matched, _ := doublestar.Match(glob, fmt.Sprintf("%s/%s", namespace, name)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestWebhookServices(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/webhooks/services.yaml")
+
+	path := github.String("fixtures/checks/webhooks/services.yaml")
+	wantAnnotations(t, candidates.Check(),
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(30), AnnotationLevel: github.String("failure")},
+		&github.CheckRunAnnotation{Path: path, StartLine: github.Int(43), AnnotationLevel: github.String("failure")},
+	)
+}
+
+func TestWebhookServicesAllowed(t *testing.T) {
+	candidates := fixtureCandidates(t, &KubeValidatorConfigPolicies{
+		WebhookServices: &KubeValidatorConfigWebhookServices{Services: []string{"admission/*"}},
+	}, "fixtures/checks/webhooks/services.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
+
+func TestWebhookServicesWithoutNamespace(t *testing.T) {
+	candidates := fixtureCandidates(t, nil, "fixtures/checks/webhooks/services-without-namespace.yaml")
+
+	wantAnnotations(t, candidates.Check())
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+)
+
+const windowLayout = "15:04"
+
+// location returns the time zone of the window
+func (w *KubeValidatorConfigWindow) location() (*time.Location, error) {
+	if w.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(w.TimeZone)
+}
+
+// valid returns false when the start, end or time zone of the window can't be
+// parsed
+func (w *KubeValidatorConfigWindow) valid() bool {
+	if _, err := time.Parse(windowLayout, w.Start); err != nil {
+		return false
+	}
+	if _, err := time.Parse(windowLayout, w.End); err != nil {
+		return false
+	}
+	_, err := w.location()
+	return err == nil
+}
+
+// contains returns true when t is within the window
+func (w *KubeValidatorConfigWindow) contains(t time.Time) bool {
+	location, err := w.location()
+	if err != nil {
+		return true
+	}
+	start, err := time.Parse(windowLayout, w.Start)
+	if err != nil {
+		return true
+	}
+	end, err := time.Parse(windowLayout, w.End)
+	if err != nil {
+		return true
+	}
+	t = t.In(location)
+	minute := t.Hour()*60 + t.Minute()
+	from, until := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from <= until {
+		return minute >= from && minute < until
+	}
+	return minute >= from || minute < until
+}
+
+func (w *KubeValidatorConfigWindow) String() string {
+	zone := w.TimeZone
+	if zone == "" {
+		zone = "UTC"
+	}
+	return fmt.Sprintf("%s-%s %s", w.Start, w.End, zone)
+}
+
+// policiesScheduled returns true unless a policy window is configured and the
+// current time is outside of it
+func (config *KubeValidatorConfig) policiesScheduled() bool {
+	if config.Spec == nil || config.Spec.PolicyWindow == nil {
+		return true
+	}
+	return config.Spec.PolicyWindow.contains(now())
+}
+
+// policyWindowSummary notes whether policies ran when a policy window is
+// configured, or returns an empty string
+func (config *KubeValidatorConfig) policyWindowSummary() string {
+	if config.Spec == nil || config.Spec.PolicyWindow == nil {
+		return ""
+	}
+	if config.schemasOnly {
+		return fmt.Sprintf("Only schemas were validated, as policies only run within the policy window (%s).", config.Spec.PolicyWindow)
+	}
+	return fmt.Sprintf("Schemas and policies were validated within the policy window (%s).", config.Spec.PolicyWindow)
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestPolicyWindowContains(t *testing.T) {
+	overnight := &KubeValidatorConfigWindow{Start: "22:00", End: "06:00", TimeZone: "Europe/Stockholm"}
+	daytime := &KubeValidatorConfigWindow{Start: "09:00", End: "17:30"}
+	for _, test := range []struct {
+		window *KubeValidatorConfigWindow
+		t      time.Time
+		want   bool
+	}{
+		{overnight, time.Date(2026, 10, 15, 21, 0, 0, 0, time.UTC), true},
+		{overnight, time.Date(2026, 10, 15, 3, 59, 0, 0, time.UTC), true},
+		{overnight, time.Date(2026, 10, 15, 4, 0, 0, 0, time.UTC), false},
+		{overnight, time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC), false},
+		{daytime, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), true},
+		{daytime, time.Date(2026, 10, 15, 17, 30, 0, 0, time.UTC), false},
+	} {
+		if got := test.window.contains(test.t); got != test.want {
+			t.Errorf("%s contains %s = %v, wanted %v", test.window, test.t, got, test.want)
+		}
+	}
+}
+
+func TestInvalidPolicyWindow(t *testing.T) {
+	for _, window := range []*KubeValidatorConfigWindow{
+		{Start: "10pm", End: "06:00"},
+		{Start: "22:00", End: "06:00", TimeZone: "Nowhere/Special"},
+	} {
+		config := &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{PolicyWindow: window}}
+		if config.Valid() {
+			t.Errorf("expected %s to be invalid", window)
+		}
+	}
+}
+
+func TestPolicyWindowSkipsPoliciesOutsideOfIt(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+
+	config := &KubeValidatorConfig{Spec: &KubeValidatorConfigSpec{
+		PolicyWindow: &KubeValidatorConfigWindow{Start: "22:00", End: "06:00"},
+	}}
+	if config.policiesScheduled() {
+		t.Fatal("expected policies not to be scheduled outside of the window")
+	}
+	config.schemasOnly = true
+
+	client, mux, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+	c := &Context{Ctx: &ctx, Github: client}
+	mux.HandleFunc("/repos/o/r/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var opt github.CreateCheckRunOptions
+		json.NewDecoder(r.Body).Decode(&opt)
+		if want := "Only schemas were validated"; !strings.Contains(opt.Output.GetSummary(), want) {
+			t.Errorf("expected %q in the summary, got %s", want, opt.Output.GetSummary())
+		}
+		if want := "(schemas only)"; !strings.Contains(opt.Output.GetTitle(), want) {
+			t.Errorf("expected %q in the title, got %s", want, opt.Output.GetTitle())
+		}
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+
+	candidates := withoutSchemas(fixtureCandidates(t, nil, "fixtures/checks/containers/duplicate-names.yaml"))
+	annotations := candidates.ValidateSchemas()
+	if len(annotations) != 0 {
+		t.Errorf("expected policies to be skipped, got %d annotations", len(annotations))
+	}
+	startedAt := time.Now()
+	if err := c.createFinalCheckRun(&startedAt, gistTestEvent(), candidates, annotations, config); err != nil {
+		t.Fatal(err)
+	}
+
+	now = func() time.Time { return time.Date(2026, 10, 15, 23, 0, 0, 0, time.UTC) }
+	if !config.policiesScheduled() {
+		t.Error("expected policies to be scheduled within the window")
+	}
+}